@@ -0,0 +1,114 @@
+// Command builddict counts the words in text files and writes them as a word list in the "Word,Count Per Billion"
+// format of words.csv, so that domain dictionaries can be built reproducibly instead of cleaned up by hand.
+//
+// Usage:
+//
+//	builddict [flags] [file ...]
+//
+// Text is read from the files, or from standard input if there are none.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/YakDriver/master-sifo-dyas/sifo"
+)
+
+func main() {
+	output := flag.String("o", "", "write the word list to this file instead of standard output")
+	keepCase := flag.Bool("keep-case", false, "keep words as written instead of lowercasing them")
+	apostrophes := flag.String("apostrophes", "split", "apostrophes between letters: split, keep or remove")
+	hyphens := flag.String("hyphens", "split", "hyphens between letters: split, keep or remove")
+	minCount := flag.Int64("min", 1, "drop words seen fewer times than this")
+	top := flag.Int("top", 0, "keep only the N most frequent words (0 keeps all)")
+	stop := flag.String("stop", "", "file of words to leave out, one per line")
+	flag.Parse()
+
+	opts := sifo.BuildOptions{
+		KeepCase: *keepCase,
+		MinCount: *minCount,
+		TopN:     *top,
+	}
+
+	var err error
+	if opts.Apostrophes, err = punctuationMode(*apostrophes); err != nil {
+		fail(err)
+	}
+	if opts.Hyphens, err = punctuationMode(*hyphens); err != nil {
+		fail(err)
+	}
+
+	if *stop != "" {
+		file, err := os.Open(*stop)
+		if err != nil {
+			fail(err)
+		}
+		opts.StopWords, err = sifo.ReadWordSet(file)
+		file.Close()
+		if err != nil {
+			fail(err)
+		}
+	}
+
+	var input io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		var readers []io.Reader
+		for _, name := range flag.Args() {
+			file, err := os.Open(name)
+			if err != nil {
+				fail(err)
+			}
+			defer file.Close()
+			// a newline between files keeps the last word of one from running into the first word of the next
+			readers = append(readers, file, newline{})
+		}
+		input = io.MultiReader(readers...)
+	}
+
+	words, err := sifo.BuildDictionary(input, opts)
+	if err != nil {
+		fail(err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fail(err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := sifo.WriteWords(out, words); err != nil {
+		fail(err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d words\n", len(words))
+}
+
+func punctuationMode(mode string) (sifo.PunctuationMode, error) {
+	switch mode {
+	case "split":
+		return sifo.PunctuationSplit, nil
+	case "keep":
+		return sifo.PunctuationKeep, nil
+	case "remove":
+		return sifo.PunctuationRemove, nil
+	}
+	return 0, fmt.Errorf("unknown punctuation mode %q, want split, keep or remove", mode)
+}
+
+// newline is a reader of a single newline.
+type newline struct{}
+
+func (newline) Read(p []byte) (int, error) {
+	return copy(p, "\n"), io.EOF
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
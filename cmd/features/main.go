@@ -0,0 +1,55 @@
+// Command features regenerates the feature tables shipped next to words.csv (prefixes.csv, suffixes.csv, middles.csv
+// and partial_words.csv) from a word list, so that they always match the words they were counted from.
+//
+// Usage:
+//
+//	features [flags]
+//
+// Each table keeps its most common features, most common first, with ties in alphabetical order.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/YakDriver/master-sifo-dyas/sifo"
+)
+
+func main() {
+	wordsFile := flag.String("words", "words.csv", "word list to count features from")
+	dir := flag.String("dir", ".", "directory to write the tables to")
+	prefixes := flag.Int("prefixes", sifo.DefaultTableLimits.Prefixes, "keep the N most common prefixes (0 keeps all)")
+	suffixes := flag.Int("suffixes", sifo.DefaultTableLimits.Suffixes, "keep the N most common suffixes (0 keeps all)")
+	middles := flag.Int("middles", sifo.DefaultTableLimits.Middles, "keep the N most common middles (0 keeps all)")
+	partialWords := flag.Int("partial-words", sifo.DefaultTableLimits.PartialWords, "keep the N most common partial words (0 keeps all)")
+	flag.Parse()
+
+	file, err := os.Open(*wordsFile)
+	if err != nil {
+		fail(err)
+	}
+	words, err := sifo.ReadWords(file, sifo.ReadOptions{Format: sifo.FormatFromFilename(*wordsFile)})
+	file.Close()
+	if err != nil {
+		fail(err)
+	}
+
+	tables := sifo.NewFeatureTables(words, sifo.English, sifo.TableLimits{
+		Prefixes:     *prefixes,
+		Suffixes:     *suffixes,
+		Middles:      *middles,
+		PartialWords: *partialWords,
+	})
+	if err := tables.Save(*dir); err != nil {
+		fail(err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %d prefixes, %d suffixes, %d middles and %d partial words from %d words\n",
+		len(tables.Prefixes), len(tables.Suffixes), len(tables.Middles), len(tables.PartialWords), len(words))
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -7,69 +7,76 @@ import (
 )
 
 func main() {
-	words := sifo.LoadWords("words.csv")
-	prefixes, suffixes := sifo.PrefixesAndSuffixes(words)
-	middles := sifo.Middles(words)
-
-	fmt.Printf("Prefixes: %d\n", len(prefixes))
-	fmt.Printf("Suffixes: %d\n", len(suffixes))
-	fmt.Printf("Middles: %d\n", len(middles))
-
-	antiPrefixes := sifo.AntiPrefixes(words)
-	antiSuffixes := sifo.AntiSuffixes(words)
-	antiMiddles := sifo.AntiMiddles(words)
-
-	fmt.Printf("Anti Prefixes: %d\n", len(antiPrefixes))
-	fmt.Printf("Anti Suffixes: %d\n", len(antiSuffixes))
-	fmt.Printf("Anti Middles: %d\n", len(antiMiddles))
-
-	wordPatterns := sifo.WordPatterns(words)
-	vowelGroups := sifo.VowelGroups(words)
-	consonantGroups := sifo.ConsonantGroups(words)
+	dict, err := sifo.LoadOrBuildDictionary("words.csv", "words.dict", sifo.English)
+	if err != nil {
+		fmt.Printf("Error loading dictionary: %v\n", err)
+		return
+	}
+	words := dict.Words
 
-	fmt.Printf("Word Patterns: %d\n", len(wordPatterns))
-	fmt.Printf("Vowel Groups: %d\n", len(vowelGroups))
-	fmt.Printf("Consonant Groups: %d\n", len(consonantGroups))
+	fmt.Printf("Prefixes: %d\n", len(dict.Prefixes))
+	fmt.Printf("Suffixes: %d\n", len(dict.Suffixes))
+	fmt.Printf("Middles: %d\n", len(dict.Middles))
 
-	vowelConsonantBoundaries := sifo.VowelConsonantBoundaries(words)
-	ConsonantVowelBoundaries := sifo.ConsonantVowelBoundaries(words)
+	fmt.Printf("Anti Prefixes: %d\n", len(dict.AntiPrefixes))
+	fmt.Printf("Anti Suffixes: %d\n", len(dict.AntiSuffixes))
+	fmt.Printf("Anti Middles: %d\n", len(dict.AntiMiddles))
 
-	fmt.Printf("Vowel Consonant Boundaries: %d\n", len(vowelConsonantBoundaries))
-	fmt.Printf("Consonant Vowel Boundaries: %d\n", len(ConsonantVowelBoundaries))
+	fmt.Printf("Word Patterns: %d\n", len(dict.WordPatterns))
+	fmt.Printf("Vowel Groups: %d\n", len(dict.VowelGroups))
+	fmt.Printf("Consonant Groups: %d\n", len(dict.ConsonantGroups))
 
-	//if 1 == 1 {
-	//	return
-	//}
+	fmt.Printf("Vowel Consonant Boundaries: %d\n", len(dict.VowelConsonantBoundaries))
+	fmt.Printf("Consonant Vowel Boundaries: %d\n", len(dict.ConsonantVowelBoundaries))
 
-	if 1 == 0 {
-		partialWords := sifo.CreatePartialWordDictionary(words)
-		if err := sifo.WriteCSV(partialWords, "partial_words.csv"); err != nil {
-			fmt.Printf("Error writing to CSV: %v\n", err)
-		} else {
-			fmt.Println("Partial word dictionary written to partial_words.csv")
-		}
+	dict.CloseMatches = sifo.NewCloseMatchIndex(words, 2)
+	dict.Phonetics = sifo.NewPhoneticIndex(words)
+	dict.Anagrams = sifo.NewAnagramIndex(words)
+	dict.Reversals = true
+	dict.WordTrie = sifo.NewTrie(words)
+	partialWords, err := sifo.LoadCounts(sifo.PartialWordsFile)
+	if err != nil {
+		fmt.Printf("Error loading partial words: %v\n", err)
+		return
+	}
+	dict.PartialWordTrie = sifo.NewTrie(partialWords)
+	dict.TagWeights = map[sifo.Tag]float64{
+		sifo.TagProperNoun:   0.5,
+		sifo.TagAbbreviation: 0.5,
+		sifo.TagArchaic:      0.75,
+		sifo.TagForeign:      0.5,
 	}
 
-	dict := sifo.Dictionary{
-		Words:                    words,
-		Prefixes:                 prefixes,
-		Suffixes:                 suffixes,
-		Middles:                  middles,
-		AntiPrefixes:             antiPrefixes,
-		AntiSuffixes:             antiSuffixes,
-		AntiMiddles:              antiMiddles,
-		WordPatterns:             wordPatterns,
-		VowelGroups:              vowelGroups,
-		ConsonantGroups:          consonantGroups,
-		VowelConsonantBoundaries: vowelConsonantBoundaries,
-		ConsonantVowelBoundaries: ConsonantVowelBoundaries,
+	blocklist, err := sifo.LoadBlocklist("blocklist.txt")
+	if err != nil {
+		fmt.Printf("Error loading blocklist: %v\n", err)
+		return
 	}
+	for _, word := range dict.Overlay.Tagged(sifo.TagOffensive) {
+		blocklist[word] = true
+	}
+	dict.Guard = &sifo.Guard{Blocklist: blocklist, Embedded: true, Forbid: true}
 
-	bestCipher := sifo.FindBestCipher(dict, 10000)
+	bestCipher, err := sifo.FindBestCipher(dict, 10000)
+	if err != nil {
+		fmt.Printf("Error finding a cipher: %v\n", err)
+		return
+	}
 	sifo.Score(dict, bestCipher, true)
 
 	fmt.Println("Best Cipher:")
-	for k, v := range bestCipher {
-		fmt.Printf("%s -> %s\n", k, string(v))
+	if key, err := sifo.FormatKey(bestCipher); err == nil {
+		fmt.Printf("Key: %s\n", key)
+	}
+	if cycles, err := sifo.FormatCycles(bestCipher); err == nil {
+		fmt.Printf("Cycles: %s\n", cycles)
+	}
+	fmt.Print(sifo.FormatMarkdown(bestCipher))
+
+	// Show how far the search moved from the best known cipher.
+	if d, err := sifo.Difference(sifo.WarmHoldCipher(), bestCipher); err == nil {
+		swaps, _ := sifo.CayleyDistance(sifo.WarmHoldCipher(), bestCipher)
+		cycles, _ := sifo.FormatCycles(d)
+		fmt.Printf("Best = WarmHold ∘ %s (%d swaps)\n", cycles, swaps)
 	}
 }
@@ -0,0 +1,48 @@
+package sifo
+
+import (
+	"slices"
+)
+
+// AnagramIndex maps the sorted-letter signature of each word to the words with those letters, so that "does an
+// encoded word use the same letters as a real word" is a single lookup.
+type AnagramIndex map[string][]string
+
+// NewAnagramIndex builds the signature index over the words.
+func NewAnagramIndex(words map[string]int64) AnagramIndex {
+	idx := make(AnagramIndex)
+	for word := range words {
+		signature := letterSignature(word)
+		idx[signature] = append(idx[signature], word)
+	}
+	for _, anagrams := range idx {
+		slices.Sort(anagrams)
+	}
+	return idx
+}
+
+// Anagrams returns the indexed words, other than word itself, that are made of the same letters as word.
+func (idx AnagramIndex) Anagrams(word string) []string {
+	var anagrams []string
+	for _, w := range idx[letterSignature(word)] {
+		if w != word {
+			anagrams = append(anagrams, w)
+		}
+	}
+	return anagrams
+}
+
+// letterSignature returns the letters of word in sorted order, which is the same for all of its anagrams. For
+// example, "lots" and "slot" are both "lost".
+func letterSignature(word string) string {
+	letters := []rune(word)
+	slices.Sort(letters)
+	return string(letters)
+}
+
+// reverseWord returns word spelled backwards.
+func reverseWord(word string) string {
+	letters := []rune(word)
+	slices.Reverse(letters)
+	return string(letters)
+}
@@ -0,0 +1,73 @@
+package sifo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnagramIndex(t *testing.T) {
+	idx := NewAnagramIndex(map[string]int64{
+		"lots": 1,
+		"slot": 1,
+		"lost": 1,
+		"rest": 1,
+	})
+
+	tests := []struct {
+		word     string
+		expected []string
+	}{
+		{"tols", []string{"lost", "lots", "slot"}}, // Not a word, three anagrams
+		{"lots", []string{"lost", "slot"}},         // A word is not its own anagram
+		{"erst", []string{"rest"}},                 // Single anagram
+		{"rust", nil},                              // No anagrams
+		{"", nil},                                  // Empty string
+	}
+
+	for _, test := range tests {
+		result := idx.Anagrams(test.word)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("Anagrams(%q) = %v; want %v", test.word, result, test.expected)
+		}
+	}
+}
+
+func TestReverseWord(t *testing.T) {
+	tests := []struct {
+		word     string
+		expected string
+	}{
+		{"stop", "pots"},
+		{"level", "level"},
+		{"añb", "bña"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		result := reverseWord(test.word)
+		if result != test.expected {
+			t.Errorf("reverseWord(%q) = %q; want %q", test.word, result, test.expected)
+		}
+	}
+}
+
+func TestScoreAnagramsAndReversals(t *testing.T) {
+	// "ab" encodes to "ba" under a cipher that swaps "a" and "b". "ba" is not a word, but it is an anagram of "ab"
+	// and "ab" reversed.
+	cipher := completeCipher(t, Cipher{"a": "b", "b": "a"})
+	dict := Dictionary{
+		Words: map[string]int64{"ab": 1},
+	}
+
+	base := Score(dict, cipher, false)
+
+	dict.Anagrams = NewAnagramIndex(dict.Words)
+	if got, want := Score(dict, cipher, false), base+anagramWeight; got != want {
+		t.Errorf("Score() with anagrams = %.4f; want %.4f", got, want)
+	}
+
+	dict.Reversals = true
+	if got, want := Score(dict, cipher, false), base+anagramWeight+reversalWeight; got != want {
+		t.Errorf("Score() with anagrams and reversals = %.4f; want %.4f", got, want)
+	}
+}
@@ -0,0 +1,158 @@
+package sifo
+
+import (
+	"bufio"
+	"cmp"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// PunctuationMode says what to do with an apostrophe or hyphen between two letters when building a dictionary.
+// Apostrophes and hyphens that are not between letters always separate words.
+type PunctuationMode int
+
+const (
+	PunctuationSplit  PunctuationMode = iota // "don't" is "don" and "t"
+	PunctuationKeep                          // "don't" is "don't"
+	PunctuationRemove                        // "don't" is "dont"
+)
+
+// BuildOptions control how BuildDictionary turns text into a word list.
+type BuildOptions struct {
+	KeepCase    bool            // keep words as written instead of lowercasing them
+	Apostrophes PunctuationMode // ' and ’
+	Hyphens     PunctuationMode // - and ‐
+	MinCount    int64           // drop words seen fewer times than this in the text
+	TopN        int             // keep only the N most frequent words; 0 keeps all
+	StopWords   map[string]bool // words to leave out, such as "the" or names, in any case
+}
+
+// BuildDictionary counts the words in text and returns them with counts per billion, the units of words.csv. Counts
+// are relative to every word in the text, including stop words and words dropped by MinCount or TopN, so they are
+// comparable with lists built from other text. Write the result with WriteWords.
+func BuildDictionary(r io.Reader, opts BuildOptions) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	var total int64
+
+	reader := bufio.NewReader(r)
+	var word strings.Builder
+	var pending rune // apostrophe or hyphen seen after a letter, kept until the next rune shows if it is inside a word
+	flush := func() {
+		if word.Len() > 0 {
+			w := word.String()
+			if !opts.KeepCase {
+				w = strings.ToLower(w)
+			}
+			counts[w]++
+			total++
+			word.Reset()
+		}
+		pending = 0
+	}
+
+	for {
+		char, _, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if unicode.IsLetter(char) {
+			if pending != 0 {
+				switch punctuationMode(pending, opts) {
+				case PunctuationKeep:
+					word.WriteRune(pending)
+				case PunctuationSplit:
+					flush()
+				}
+				pending = 0
+			}
+			word.WriteRune(char)
+			continue
+		}
+
+		if word.Len() > 0 && pending == 0 && isInternalPunctuation(char) {
+			pending = char
+			continue
+		}
+		flush()
+	}
+	flush()
+
+	stop := make(map[string]bool, len(opts.StopWords))
+	for w, ok := range opts.StopWords {
+		if ok {
+			stop[strings.ToLower(w)] = true
+		}
+	}
+
+	words := make(map[string]int64)
+	var kept []string
+	for w, count := range counts {
+		if count < opts.MinCount || stop[strings.ToLower(w)] {
+			continue
+		}
+		kept = append(kept, w)
+	}
+	if opts.TopN > 0 && len(kept) > opts.TopN {
+		sortByCount(kept, counts)
+		kept = kept[:opts.TopN]
+	}
+	for _, w := range kept {
+		words[w] = perBillion(counts[w], total)
+	}
+
+	return words, nil
+}
+
+func isInternalPunctuation(char rune) bool {
+	switch char {
+	case '\'', '’', '-', '‐':
+		return true
+	}
+	return false
+}
+
+func punctuationMode(char rune, opts BuildOptions) PunctuationMode {
+	if char == '-' || char == '‐' {
+		return opts.Hyphens
+	}
+	return opts.Apostrophes
+}
+
+// sortByCount sorts words from most to least frequent, alphabetically among equal counts.
+func sortByCount(words []string, counts map[string]int64) {
+	slices.SortFunc(words, func(a, b string) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+}
+
+// WriteWords writes a word list in the "Word,Count Per Billion" format of words.csv, most frequent first.
+func WriteWords(w io.Writer, words map[string]int64) error {
+	return writeCounts(w, []string{"Word", "Count Per Billion"}, words)
+}
+
+// ReadWordSet reads a set of words, one per line, such as a stop-list. Blank lines and lines starting with "#" are
+// ignored.
+func ReadWordSet(r io.Reader) (map[string]bool, error) {
+	set := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[line] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
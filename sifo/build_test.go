@@ -0,0 +1,120 @@
+package sifo
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildDictionary(t *testing.T) {
+	text := "Don't stop. Well-known words don't stop, they're well known! ‘Quoted’ -dash- words"
+
+	tests := []struct {
+		name     string
+		opts     BuildOptions
+		expected map[string]int64
+	}{
+		{
+			name: "defaults",
+			opts: BuildOptions{MinCount: 2},
+			expected: map[string]int64{
+				"don":   perBillion(2, 16),
+				"t":     perBillion(2, 16),
+				"stop":  perBillion(2, 16),
+				"well":  perBillion(2, 16),
+				"known": perBillion(2, 16),
+				"words": perBillion(2, 16),
+			},
+		},
+		{
+			name: "keep apostrophes, remove hyphens",
+			opts: BuildOptions{Apostrophes: PunctuationKeep, Hyphens: PunctuationRemove, MinCount: 2},
+			expected: map[string]int64{
+				"don't": perBillion(2, 12),
+				"stop":  perBillion(2, 12),
+				"words": perBillion(2, 12),
+			},
+		},
+		{
+			name: "keep case, stop words, top N",
+			opts: BuildOptions{KeepCase: true, StopWords: map[string]bool{"stop": true}, TopN: 2},
+			expected: map[string]int64{
+				"known": perBillion(2, 16),
+				"t":     perBillion(2, 16),
+			},
+		},
+		{
+			name: "mixed case stop words",
+			opts: BuildOptions{StopWords: map[string]bool{"Stop": true, "DON": true, "T": true}, MinCount: 2},
+			expected: map[string]int64{
+				"well":  perBillion(2, 16),
+				"known": perBillion(2, 16),
+				"words": perBillion(2, 16),
+			},
+		},
+		{
+			name: "keep case, mixed case stop words",
+			opts: BuildOptions{KeepCase: true, StopWords: map[string]bool{"DON": true, "quoted": true}},
+			expected: map[string]int64{
+				"stop":  perBillion(2, 16),
+				"Well":  perBillion(1, 16),
+				"well":  perBillion(1, 16),
+				"known": perBillion(2, 16),
+				"words": perBillion(2, 16),
+				"t":     perBillion(2, 16),
+				"they":  perBillion(1, 16),
+				"re":    perBillion(1, 16),
+				"dash":  perBillion(1, 16),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		words, err := BuildDictionary(strings.NewReader(text), test.opts)
+		if err != nil {
+			t.Fatalf("BuildDictionary(%s) error = %v", test.name, err)
+		}
+		if !reflect.DeepEqual(words, test.expected) {
+			t.Errorf("BuildDictionary(%s) = %v; want %v", test.name, words, test.expected)
+		}
+	}
+}
+
+func TestWriteWords(t *testing.T) {
+	words := map[string]int64{
+		"of":  33950064,
+		"the": 56271872,
+		"and": 33950064,
+	}
+
+	var buf bytes.Buffer
+	if err := WriteWords(&buf, words); err != nil {
+		t.Fatalf("WriteWords() error = %v", err)
+	}
+
+	expected := "Word,Count Per Billion\nthe,56271872\nand,33950064\nof,33950064\n"
+	if buf.String() != expected {
+		t.Errorf("WriteWords() = %q; want %q", buf.String(), expected)
+	}
+
+	read, err := ReadWords(&buf, ReadOptions{})
+	if err != nil {
+		t.Fatalf("ReadWords() error = %v", err)
+	}
+	if !reflect.DeepEqual(read, words) {
+		t.Errorf("ReadWords(WriteWords()) = %v; want %v", read, words)
+	}
+}
+
+func TestReadWordSet(t *testing.T) {
+	set, err := ReadWordSet(strings.NewReader("# stop words\nthe\n\n  of  \n"))
+	if err != nil {
+		t.Fatalf("ReadWordSet() error = %v", err)
+	}
+
+	expected := map[string]bool{"the": true, "of": true}
+	if !reflect.DeepEqual(set, expected) {
+		t.Errorf("ReadWordSet() = %v; want %v", set, expected)
+	}
+}
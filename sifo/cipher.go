@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type Cipher map[string]string
@@ -15,6 +16,26 @@ const (
 	secondThresholdFactor = 1.2
 	largeVariationsAfter  = 200
 
+	// minGiants is the fewest giants the giant strategy can vary between. When the guard forbids more giants than
+	// that allows, random ciphers the guard allows take their places, and maxSeedTries bounds the search for them.
+	minGiants    = 2
+	maxSeedTries = 10000
+
+	// closeMatchWeight is the share of a word's occurrence score given when its encoding is not a word but is a
+	// close match (see CloseMatchIndex.IsCloseMatch) to one.
+	closeMatchWeight = 2.0
+
+	// soundexWeight and metaphoneWeight are the shares of a word's occurrence score given when its encoding is not a
+	// word but sounds like one (see PhoneticIndex). Soundex codes collide often, so they count for less.
+	soundexWeight   = 0.5
+	metaphoneWeight = 1.5
+
+	// anagramWeight is the share of a word's occurrence score given when its encoding is not a word but rearranges
+	// the letters of one, as "opts" does "stop". reversalWeight is the share given when the encoding spells a word
+	// backwards, as "pots" does "stop". A reversal is also an anagram, so with both on it earns both.
+	anagramWeight  = 1.0
+	reversalWeight = 2.0
+
 	quote = "Here's to the crazy ones. The misfits. The rebels. The troublemakers. The round pegs in the square holes."
 )
 
@@ -44,27 +65,76 @@ type Dictionary struct {
 	ConsonantGroups          map[string]bool
 	VowelConsonantBoundaries map[string]bool
 	ConsonantVowelBoundaries map[string]bool
+	CloseMatches             *CloseMatchIndex // optional; nil disables close-match scoring
+	Phonetics                *PhoneticIndex   // optional; nil disables phonetic scoring
+	Corpus                   *Corpus          // optional; nil scores the dictionary words only
+	PartsOfSpeech            PartsOfSpeech    // optional; nil disables part-of-speech scoring
+	Anagrams                 AnagramIndex     // optional; nil disables anagram scoring
+	Reversals                bool             // score encodings that are words spelled backwards
+	WordTrie                 *Trie            // optional; nil disables prefix-depth scoring
+	PartialWordTrie          *Trie            // optional; a Trie over partial words; nil disables substring scoring
+	Overlay                  Overlay          // optional; excluded words neither score nor count as encodings
+	TagWeights               map[Tag]float64  // scales a word match by the weights of the encoded word's tags
+	Guard                    *Guard           // optional; nil lets encodings be any word
+	Digraphs                 []string         // optional; two-letter keys random ciphers may use, such as CommonDigraphs
+	Language                 Language         // alphabet and vowels the sets were built with; zero value is English
+}
+
+// NewDictionary builds a dictionary's prefix, suffix, middle and vowel/consonant sets from words in the language.
+// The optional scoring components are left unset.
+func NewDictionary(words map[string]int64, lang Language) Dictionary {
+	prefixes, suffixes := lang.PrefixesAndSuffixes(words)
+
+	return Dictionary{
+		Words:                    words,
+		Prefixes:                 prefixes,
+		Suffixes:                 suffixes,
+		Middles:                  lang.Middles(words),
+		AntiPrefixes:             lang.AntiPrefixes(words),
+		AntiSuffixes:             lang.AntiSuffixes(words),
+		AntiMiddles:              lang.AntiMiddles(words),
+		WordPatterns:             lang.WordPatterns(words),
+		VowelGroups:              lang.VowelGroups(words),
+		ConsonantGroups:          lang.ConsonantGroups(words),
+		VowelConsonantBoundaries: lang.VowelConsonantBoundaries(words),
+		ConsonantVowelBoundaries: lang.ConsonantVowelBoundaries(words),
+		Language:                 lang,
+	}
 }
 
 var restarts int
 
-func FindBestCipher(dict Dictionary, iterations int) Cipher {
+// FindBestCipher searches for the cipher with the highest Score, starting from the giants that are valid over the
+// dictionary's alphabet. It returns an error if a random cipher it starts a search from is not valid, as with
+// digraphs that are not lower case letters of the alphabet, or if too few giants are left to start from and no
+// random cipher the guard allows can be found to take their places.
+func FindBestCipher(dict Dictionary, iterations int) (Cipher, error) {
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	lang := dict.Language.orDefault()
+
 	gts := giants(dict)
+	if len(gts) < minGiants {
+		seeds, err := seedGiants(r, dict, minGiants-len(gts))
+		if err != nil {
+			return nil, err
+		}
+		gts = append(gts, seeds...)
+	}
 	restarts++
 
 	minGiantScore := gts[0].score
 	for _, gt := range gts {
 		fmt.Printf("%d. Giant %s: %.4f\n", restarts, gt.name, gt.score)
-		fmt.Printf("  %s\n", Encode(quote, gt.cipher))
+		fmt.Printf("  %s\n", lang.Encode(quote, gt.cipher))
 		if gt.score < minGiantScore {
 			minGiantScore = gt.score
 		}
 	}
 
-	var bestCipher Cipher
-
-	bestCipher = generateRandomCipherSimple(r)
+	bestCipher, err := seedCipher(r, dict)
+	if err != nil {
+		return nil, err
+	}
 
 	objectiveAchieved := false
 
@@ -73,7 +143,11 @@ func FindBestCipher(dict Dictionary, iterations int) Cipher {
 		if objectiveAchieved {
 			break
 		}
-		bestCipher, objectiveAchieved = iterationSearch(StrategyRandom, generateRandomCipherSimple(r), gts, dict, iterations, []Threshold{{minGiantScore / firstThresholdFactor, 0}})
+		seed, err := seedCipher(r, dict)
+		if err != nil {
+			return nil, err
+		}
+		bestCipher, objectiveAchieved = iterationSearch(StrategyRandom, seed, gts, dict, iterations, []Threshold{{minGiantScore / firstThresholdFactor, 0}})
 		if objectiveAchieved {
 			bestCipher, objectiveAchieved = iterationSearch(StrategyElastic, bestCipher, gts, dict, iterations, []Threshold{
 				{minGiantScore / secondThresholdFactor, iterations / 3},
@@ -85,10 +159,39 @@ func FindBestCipher(dict Dictionary, iterations int) Cipher {
 
 	if objectiveAchieved {
 		fmt.Printf("Objective achieved\n")
-		return bestCipher
+		return bestCipher, nil
 	}
 
-	return bestCipher
+	return bestCipher, nil
+}
+
+// seedCipher returns a random cipher to start a search from, or an error if it is not valid over the dictionary's
+// alphabet.
+func seedCipher(r *rand.Rand, dict Dictionary) (Cipher, error) {
+	cipher := randomCipher(r, dict)
+	if err := Validate(cipher, dict.Language.orDefault().Alphabet); err != nil {
+		return nil, fmt.Errorf("random cipher: %w", err)
+	}
+	return cipher, nil
+}
+
+// seedGiants returns n random ciphers the guard allows, to search from in place of forbidden giants.
+func seedGiants(r *rand.Rand, dict Dictionary, n int) ([]Giant, error) {
+	var seeds []Giant
+	for tries := 0; len(seeds) < n; tries++ {
+		if tries == maxSeedTries {
+			return nil, fmt.Errorf("the guard forbids the giants and all %d random ciphers tried", maxSeedTries)
+		}
+		cipher, err := seedCipher(r, dict)
+		if err != nil {
+			return nil, err
+		}
+		if score := Score(dict, cipher, false); !math.IsInf(score, -1) {
+			seeds = append(seeds, Giant{name: fmt.Sprintf("Random%d", len(seeds)+1), cipher: cipher, score: score})
+		}
+	}
+	fmt.Printf("Seeded %d random giants the guard allows\n", len(seeds))
+	return seeds, nil
 }
 
 // iterationSearch uses the strategy and returns the best cipher found and the high score.
@@ -132,7 +235,7 @@ outerLoop:
 				fmt.Printf("%d. First threshold reached (%.4f > %.4f): %d iterations\n", restarts, maxHighScore, thresholds[0].score, i)
 				return bestCipher, true
 			}
-			tryCipher = generateRandomCipherSimple(r)
+			tryCipher = randomCipher(r, dict)
 		case StrategyElastic:
 			// returns in two ways: when the iterations are exhausted or if a threshold is not reached
 			if curThreshold < len(thresholds) && maxHighScore > thresholds[curThreshold].score && !thresholdsPassed[curThreshold] {
@@ -153,6 +256,9 @@ outerLoop:
 			}
 
 			tryCipher = varyCipher(bestCipher, r, 1+r.Intn(2))
+			if len(dict.Digraphs) > 0 && r.Intn(4) == 0 {
+				tryCipher = swapDigraph(tryCipher, r, dict.Digraphs)
+			}
 
 			if itsSinceHighScore > 1800 {
 				break outerLoop
@@ -195,7 +301,7 @@ outerLoop:
 		}
 
 		highScore := Score(dict, tryCipher, false)
-		if int64(highScore) > int64(maxHighScore) {
+		if improves(highScore, maxHighScore) {
 			itsSinceHighScore = 0
 			maxHighScore = highScore
 			bestCipher = tryCipher
@@ -247,20 +353,63 @@ func whichThreshold(i int) string {
 	return ""
 }
 
+// Score rates how much the cipher's encodings of the dictionary's words look like words. A cipher that is not valid
+// over the dictionary's alphabet (see Validate), or that the guard forbids, scores -Inf so the search never keeps it.
 func Score(dict Dictionary, cipher Cipher, output bool) float64 {
 	var score float64
+	var anagrams, reversals, blocked []string
+	forbidden := false
+	lang := dict.Language.orDefault()
+	if err := Validate(cipher, lang.Alphabet); err != nil {
+		if output {
+			fmt.Printf("Score: -Inf (%v)\n", err)
+		}
+		return math.Inf(-1)
+	}
+
 	i := 0
 	for word, ogOccurence := range dict.Words {
+		if dict.Overlay.Excludes(word) {
+			continue
+		}
 		i++
-		encodedWord := encodeWord(word, cipher)
-		if encOccurence, ok := dict.Words[encodedWord]; ok {
+		encodedWord := lang.encodeWord(word, cipher)
+
+		if dict.Guard != nil {
+			if b := dict.Guard.blocked(encodedWord); b != "" {
+				if dict.Guard.Forbid {
+					if !output {
+						return math.Inf(-1)
+					}
+					forbidden = true
+				}
+
+				s := guardWeight * occurrenceScore(ogOccurence)
+				score = score - s
+
+				if output {
+					blocked = append(blocked, fmt.Sprintf("%s -> %s (blocked %s, score -%.4f)", word, encodedWord, b, s))
+				}
+			}
+		}
+
+		if encOccurence, ok := dict.Words[encodedWord]; ok && !dict.Overlay.Excludes(encodedWord) {
 			s := float64(max(occurrenceScore(ogOccurence), occurrenceScore(encOccurence)))
-			s *= 10
+			s *= 10 * dict.Overlay.tagWeight(encodedWord, dict.TagWeights)
 			score = score + s
 
 			if output {
 				fmt.Printf("%d. %s -> %s (score %.4f)\n", i, word, encodedWord, s)
 			}
+
+			if dict.PartsOfSpeech != nil && dict.PartsOfSpeech.Compatible(word, encodedWord) {
+				s := posWeight * occurrenceScore(ogOccurence)
+				score = score + s
+
+				if output {
+					fmt.Printf("%d. %s -> %s (same part of speech, score %.4f)\n", i, word, encodedWord, s)
+				}
+			}
 			continue
 		}
 
@@ -271,6 +420,92 @@ func Score(dict Dictionary, cipher Cipher, output bool) float64 {
 		if output {
 			//fmt.Printf("%d. %s -> %s (pattern match, score %.4f, epc %d)\n", i, word, encodedWord, s, epc)
 		}
+
+		if dict.WordTrie != nil {
+			depth := prefixDepth(encodedWord, dict.WordTrie)
+			s := prefixWeight * depth * occurrenceScore(ogOccurence)
+			score = score + s
+
+			if output && depth == 1 {
+				fmt.Printf("%d. %s -> %s (starts a word, score %.4f)\n", i, word, encodedWord, s)
+			}
+		}
+
+		if dict.PartialWordTrie != nil {
+			depth := substringDepth(encodedWord, dict.PartialWordTrie)
+			s := substringWeight * depth * occurrenceScore(ogOccurence)
+			score = score + s
+
+			if output && depth == 1 {
+				fmt.Printf("%d. %s -> %s (inside a word, score %.4f)\n", i, word, encodedWord, s)
+			}
+		}
+
+		if dict.CloseMatches != nil && dict.CloseMatches.IsCloseMatch(encodedWord) {
+			s := closeMatchWeight * occurrenceScore(ogOccurence)
+			score = score + s
+
+			if output {
+				fmt.Printf("%d. %s -> %s (close match, score %.4f)\n", i, word, encodedWord, s)
+			}
+		}
+
+		if dict.Phonetics != nil {
+			s := phoneticScore(encodedWord, dict.Phonetics) * occurrenceScore(ogOccurence)
+			score = score + s
+
+			if output && s > 0 {
+				fmt.Printf("%d. %s -> %s (sounds like a word, score %.4f)\n", i, word, encodedWord, s)
+			}
+		}
+
+		if dict.Anagrams != nil {
+			if matches := dict.Anagrams.Anagrams(encodedWord); len(matches) > 0 {
+				s := anagramWeight * occurrenceScore(ogOccurence)
+				score = score + s
+
+				if output {
+					anagrams = append(anagrams, fmt.Sprintf("%s -> %s (anagram of %s, score %.4f)", word, encodedWord, strings.Join(matches, ", "), s))
+				}
+			}
+		}
+
+		if dict.Reversals {
+			reversed := reverseWord(encodedWord)
+			if _, ok := dict.Words[reversed]; ok {
+				s := reversalWeight * occurrenceScore(ogOccurence)
+				score = score + s
+
+				if output {
+					reversals = append(reversals, fmt.Sprintf("%s -> %s (%s reversed, score %.4f)", word, encodedWord, reversed, s))
+				}
+			}
+		}
+	}
+	if output && len(anagrams) > 0 {
+		fmt.Printf("Anagrams: %d\n", len(anagrams))
+		for _, a := range anagrams {
+			fmt.Printf("  %s\n", a)
+		}
+	}
+	if output && len(reversals) > 0 {
+		fmt.Printf("Reversals: %d\n", len(reversals))
+		for _, r := range reversals {
+			fmt.Printf("  %s\n", r)
+		}
+	}
+	if output && len(blocked) > 0 {
+		fmt.Printf("Blocked: %d\n", len(blocked))
+		for _, b := range blocked {
+			fmt.Printf("  %s\n", b)
+		}
+	}
+	if forbidden {
+		fmt.Printf("Score: forbidden by the guard\n")
+		return math.Inf(-1)
+	}
+	if dict.Corpus != nil {
+		score = score + ScoreCorpus(dict, dict.Corpus, cipher, output)
 	}
 	if output {
 		fmt.Printf("Score: %.4f\n", score)
@@ -292,6 +527,19 @@ func adjustEPC(epc int) float64 {
 	return math.Pow(max(float64(epc-4), 0), 1.5) * 1.3
 }
 
+// phoneticScore returns how much a word that is not in the dictionary sounds like one that is: soundexWeight if its
+// Soundex code matches a word's and metaphoneWeight if one of its Double Metaphone keys does.
+func phoneticScore(word string, phonetics *PhoneticIndex) float64 {
+	var s float64
+	if phonetics.SoundexMatch(word) {
+		s += soundexWeight
+	}
+	if phonetics.MetaphoneMatch(word) {
+		s += metaphoneWeight
+	}
+	return s
+}
+
 // occurrenceScore takes the occurrences associated with a word and returns a score of 1, 2, or 3 based on the
 // occurrence. 3 is ~95th percentile words, where s > 160000. 2 is ~80th where s > 40000. 1 is the rest.
 func occurrenceScore(s int64) float64 {
@@ -311,14 +559,15 @@ func occurrenceScore(s int64) float64 {
 // 2. Each vowel group (found with vowelGroups()) in the word matches a known vowel group in dict.VowelGroups
 // 3. Each consonant group (found with consonantGroups()) in the word matches a known consonant group in dict.ConsonantGroups
 func englishPattern(word string, dict Dictionary) int {
+	lang := dict.Language.orDefault()
 	matches := 0
-	pattern := wordPattern(word)
+	pattern := lang.wordPattern(word)
 	if dict.WordPatterns[pattern] {
 		matches++
 	}
 
 	vg := true
-	vowelGroups := vowelGroups(word)
+	vowelGroups := lang.vowelGroups(word)
 	for _, group := range vowelGroups {
 		if !dict.VowelGroups[group] {
 			vg = false
@@ -330,7 +579,7 @@ func englishPattern(word string, dict Dictionary) int {
 	}
 
 	cg := true
-	consonantGroups := consonantGroups(word)
+	consonantGroups := lang.consonantGroups(word)
 	for _, group := range consonantGroups {
 		if !dict.ConsonantGroups[group] {
 			cg = false
@@ -342,7 +591,7 @@ func englishPattern(word string, dict Dictionary) int {
 	}
 
 	vcb := true
-	vcBoundaries := vowelConsonantBoundaries(word)
+	vcBoundaries := lang.vowelConsonantBoundaries(word)
 	for _, vc := range vcBoundaries {
 		if !dict.VowelConsonantBoundaries[vc] {
 			vcb = false
@@ -354,7 +603,7 @@ func englishPattern(word string, dict Dictionary) int {
 	}
 
 	cvb := true
-	cvBoundaries := consonantVowelBoundaries(word)
+	cvBoundaries := lang.consonantVowelBoundaries(word)
 	for _, cv := range cvBoundaries {
 		if !dict.ConsonantVowelBoundaries[cv] {
 			cvb = false
@@ -387,8 +636,8 @@ func englishPattern(word string, dict Dictionary) int {
 }
 
 // patterns takes a word and returns a score based on the patterns it follows. A word gets points for each of the following patterns:
-// 2 points - isCloseMatch, based on levenshtein distance (takes too long)
-// 1 point - has a vowel
+// 2 points - close match, based on levenshtein distance (only when dict.CloseMatches is set)
+// 1 point - has one of the language's vowels
 // 1 point - has one of the prefixes
 // 1 point - has one of the suffixes
 // 1 point - has one of the middles
@@ -396,12 +645,12 @@ func patterns(word string, dict Dictionary) int {
 	score := 0
 
 	// Check if the word is a close match to any word in the dictionary
-	if 1 == 0 && isCloseMatch(word, dict.Words) {
+	if dict.CloseMatches != nil && dict.CloseMatches.IsCloseMatch(word) {
 		score += 2
 	}
 
 	// Check if the word has a vowel
-	if hasVowel(word) {
+	if dict.Language.orDefault().hasVowel(word) {
 		score += 1
 	}
 
@@ -433,17 +682,18 @@ func patterns(word string, dict Dictionary) int {
 
 func isCloseMatch(word string, words map[string]int64) bool {
 	for w := range words {
-		if levenshteinDistance(word, w) <= len(w)/3 { // 3 letter word, 1 off
+		if levenshteinDistance(word, w) <= utf8.RuneCountInString(w)/3 { // 3 letter word, 1 off
 			return true
 		}
 	}
 	return false
 }
 
-func hasVowel(word string) bool {
-	vowels := "aeiou"
+// hasVowel reports whether the word has any of the language's vowels other than its semivowels, so that "sky" has no
+// vowel in English.
+func (l Language) hasVowel(word string) bool {
 	for _, char := range word {
-		if strings.ContainsRune(vowels, char) {
+		if l.IsVowel(char) && !strings.ContainsRune(string(l.Semivowels), char) {
 			return true
 		}
 	}
@@ -454,14 +704,15 @@ func hasVowel(word string) bool {
 // prefix, the word must be at least 3 letters long. To have a 3-letter prefix, the word must be at least 4 letters
 // long.
 func hasPrefix(word string, prefixes map[string]bool) bool {
-	if len(word) >= 3 {
-		prefix := word[:2]
+	sp := newSpelling(word)
+	if sp.len() >= 3 {
+		prefix := sp.slice(0, 2)
 		if _, exists := prefixes[prefix]; exists {
 			return true
 		}
 	}
-	if len(word) >= 4 {
-		prefix := word[:3]
+	if sp.len() >= 4 {
+		prefix := sp.slice(0, 3)
 		if _, exists := prefixes[prefix]; exists {
 			return true
 		}
@@ -473,14 +724,16 @@ func hasPrefix(word string, prefixes map[string]bool) bool {
 // suffix, the word must be at least 3 letters long. To have a 3-letter suffix, the word must be at least 4 letters
 // long.
 func hasSuffix(word string, suffixes map[string]bool) bool {
-	if len(word) >= 3 {
-		suffix := word[len(word)-2:]
+	sp := newSpelling(word)
+	length := sp.len()
+	if length >= 3 {
+		suffix := sp.slice(length-2, length)
 		if _, exists := suffixes[suffix]; exists {
 			return true
 		}
 	}
-	if len(word) >= 4 {
-		suffix := word[len(word)-3:]
+	if length >= 4 {
+		suffix := sp.slice(length-3, length)
 		if _, exists := suffixes[suffix]; exists {
 			return true
 		}
@@ -493,7 +746,8 @@ func hasSuffix(word string, suffixes map[string]bool) bool {
 // 3-letter middle, the word must be at least 5 letters long. To have a 4-letter middle, the word must be at least 6
 // letters long.
 func hasMiddles(word string, middles map[string]bool) bool {
-	length := len(word)
+	sp := newSpelling(word)
+	length := sp.len()
 	if length < 4 {
 		return false
 	}
@@ -501,7 +755,7 @@ func hasMiddles(word string, middles map[string]bool) bool {
 	// Check 2-letter middles
 	if length >= 4 {
 		for i := 1; i <= length-3; i++ {
-			middle := word[i : i+2]
+			middle := sp.slice(i, i+2)
 			if _, exists := middles[middle]; !exists {
 				return false
 			}
@@ -511,7 +765,7 @@ func hasMiddles(word string, middles map[string]bool) bool {
 	// Check 3-letter middles
 	if length >= 5 {
 		for i := 1; i <= length-4; i++ {
-			middle := word[i : i+3]
+			middle := sp.slice(i, i+3)
 			if _, exists := middles[middle]; !exists {
 				return false
 			}
@@ -521,7 +775,7 @@ func hasMiddles(word string, middles map[string]bool) bool {
 	// Check 4-letter middles
 	if length >= 6 {
 		for i := 1; i <= length-5; i++ {
-			middle := word[i : i+4]
+			middle := sp.slice(i, i+4)
 			if _, exists := middles[middle]; !exists {
 				return false
 			}
@@ -536,7 +790,8 @@ func hasMiddles(word string, middles map[string]bool) bool {
 // must be at least 4 letters long. To have a 3-letter middle, the word must be at least 5 letters long. To have a
 // 4-letter middle, the word must be at least 6 letters long.
 func hasMostMiddles(word string, middles map[string]bool) bool {
-	length := len(word)
+	sp := newSpelling(word)
+	length := sp.len()
 	if length < 4 {
 		return false
 	}
@@ -547,7 +802,7 @@ func hasMostMiddles(word string, middles map[string]bool) bool {
 	// Check 2-letter middles
 	if length >= 4 {
 		for i := 1; i <= length-3; i++ {
-			middle := word[i : i+2]
+			middle := sp.slice(i, i+2)
 			totalMiddles++
 			if _, exists := middles[middle]; exists {
 				matchingMiddles++
@@ -558,7 +813,7 @@ func hasMostMiddles(word string, middles map[string]bool) bool {
 	// Check 3-letter middles
 	if length >= 5 {
 		for i := 1; i <= length-4; i++ {
-			middle := word[i : i+3]
+			middle := sp.slice(i, i+3)
 			totalMiddles++
 			if _, exists := middles[middle]; exists {
 				matchingMiddles++
@@ -569,7 +824,7 @@ func hasMostMiddles(word string, middles map[string]bool) bool {
 	// Check 4-letter middles
 	if length >= 6 {
 		for i := 1; i <= length-5; i++ {
-			middle := word[i : i+4]
+			middle := sp.slice(i, i+4)
 			totalMiddles++
 			if _, exists := middles[middle]; exists {
 				matchingMiddles++
@@ -580,10 +835,11 @@ func hasMostMiddles(word string, middles map[string]bool) bool {
 	return float64(matchingMiddles) >= (float64(totalMiddles) * float64(0.60))
 }
 
-func levenshteinDistance(a, b string) int {
+func levenshteinDistance(word1, word2 string) int {
 	// Implementation of the Levenshtein distance algorithm
 	// This function calculates the number of single-character edits (insertions, deletions, or substitutions)
 	// required to change one word into the other
+	a, b := []rune(word1), []rune(word2)
 	la, lb := len(a), len(b)
 	d := make([][]int, la+1)
 	for i := range d {
@@ -635,35 +891,20 @@ func equal(a, b Cipher) bool {
 	return true
 }
 
-func generateRandomCipherSimple(r *rand.Rand) Cipher {
-	alphabet := []string{
-		"a",
-		"b",
-		"c",
-		"d",
-		"e",
-		"f",
-		"g",
-		"h",
-		"i",
-		"j",
-		"k",
-		"l",
-		"m",
-		"n",
-		"o",
-		"p",
-		"q",
-		"r",
-		"s",
-		"t",
-		"u",
-		"v",
-		"w",
-		"x",
-		"y",
-		"z",
+// randomCipher generates a random cipher for the search, a digraph cipher if the dictionary has digraphs.
+func randomCipher(r *rand.Rand, dict Dictionary) Cipher {
+	lang := dict.Language.orDefault()
+	var cipher Cipher
+	if len(dict.Digraphs) > 0 {
+		cipher = generateRandomDigraphCipher(r, lang, dict.Digraphs)
+	} else {
+		cipher = generateRandomCipherSimple(r, lang)
 	}
+	return cipher
+}
+
+func generateRandomCipherSimple(r *rand.Rand, lang Language) Cipher {
+	alphabet := lang.Letters()
 
 	alphabetMap := shuffleMap(r, alphabet)
 
@@ -679,7 +920,7 @@ func generateRandomCipherSimple(r *rand.Rand) Cipher {
 // known but I thought I could figure a way around it. I could not. For example, "a" and "an" cannot both be keys in the same
 // cipher. This is because in decoding, you cannot know unambiguously whether two single letters are meant to be decoded as
 // a single letter or as two letters. You can get around this by using a delimiter between characters but that is undesirable.
-// This is kept for historical reasons.
+// This is kept for historical reasons. generateRandomDigraphCipher gets around it with an escape letter instead.
 func generateRandomCipher(r *rand.Rand) Cipher {
 	twos := []string{
 		"an",
@@ -820,15 +1061,37 @@ type Giant struct {
 func giants(dict Dictionary) []Giant {
 	fmt.Printf("Loading giants...\n")
 	giantsList := []Giant{
-		{"LonelyRemark", LonelyRemarkCipher(), Score(dict, LonelyRemarkCipher(), false)},
-		{"MoonPeer", MoonPeerCipher(), Score(dict, MoonPeerCipher(), false)},
-		{"WormHeld", WormHeldCipher(), Score(dict, WormHeldCipher(), false)},
-		{"WarmHold", WarmHoldCipher(), Score(dict, WarmHoldCipher(), false)},
-		{"WormHelp", WormHelpCipher(), Score(dict, WormHelpCipher(), false)},
+		{name: "LonelyRemark", cipher: LonelyRemarkCipher()},
+		{name: "MoonPeer", cipher: MoonPeerCipher()},
+		{name: "WormHeld", cipher: WormHeldCipher()},
+		{name: "WarmHold", cipher: WarmHoldCipher()},
+		{name: "WormHelp", cipher: WormHelpCipher()},
 	}
+	// The giants are English ciphers, so a dictionary in another language may leave letters they have no key for.
+	lang := dict.Language.orDefault()
+	valid := giantsList[:0]
+	for _, giant := range giantsList {
+		if err := Validate(giant.cipher, lang.Alphabet); err != nil {
+			fmt.Printf("Giant %s does not fit the %s alphabet: %v\n", giant.name, lang.Name, err)
+			continue
+		}
+		giant.score = Score(dict, giant.cipher, false)
+		valid = append(valid, giant)
+	}
+	giantsList = valid
 
 	fmt.Printf("Loaded %d giants\n", len(giantsList))
 
+	allowed := giantsList[:0]
+	for _, giant := range giantsList {
+		if math.IsInf(giant.score, -1) {
+			fmt.Printf("Giant %s is forbidden by the guard\n", giant.name)
+			continue
+		}
+		allowed = append(allowed, giant)
+	}
+	giantsList = allowed
+
 	fmt.Printf("Removing duplicate giants...\n")
 	uniqueGiants := []Giant{}
 	for i, giant1 := range giantsList {
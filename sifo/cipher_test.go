@@ -59,11 +59,21 @@ func TestHasVowel(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := hasVowel(test.word)
+		result := English.hasVowel(test.word)
 		if result != test.expected {
 			t.Errorf("hasVowel(%q) = %v; want %v", test.word, result, test.expected)
 		}
 	}
+
+	// Accented vowels count in the languages that have them.
+	if !Spanish.hasVowel("ñú") || !German.hasVowel("schön") || English.hasVowel("ñú") {
+		t.Errorf("hasVowel() does not use the language's vowels")
+	}
+
+	// English y is a vowel in word patterns but a semivowel here; a language without semivowels counts it.
+	if !English.IsVowel('y') || !Spanish.hasVowel("y") {
+		t.Errorf("hasVowel() does not tell semivowels from vowels")
+	}
 }
 
 func TestHasPrefix(t *testing.T) {
@@ -0,0 +1,165 @@
+package sifo
+
+import (
+	"sync"
+	"unicode/utf8"
+)
+
+// closeMatchCacheSize bounds the number of IsCloseMatch results a CloseMatchIndex remembers. Ciphers explored during
+// a search differ by a swap or two, so most encoded words repeat from one Score call to the next.
+const closeMatchCacheSize = 1 << 20
+
+// CloseMatchIndex is a symmetric-delete index over a word list. Every word is stored under each string that can be
+// made from it by deleting up to maxDistance letters. Two words within Levenshtein distance k of each other always
+// share such a deletion variant, so a query only needs to generate the deletions of the query word, look them up,
+// and verify the handful of candidates it finds. That keeps "is there a word within distance k" to a few map
+// lookups instead of a levenshteinDistance call against every word in the dictionary.
+type CloseMatchIndex struct {
+	maxDistance int
+	words       []string
+	deletes     map[string][]int32
+
+	mu    sync.Mutex
+	cache map[string]bool
+}
+
+// NewCloseMatchIndex builds an index over the words that can answer queries up to maxDistance. The index grows
+// quickly with maxDistance (roughly len(word)^maxDistance entries per word), so 1 or 2 is the practical range.
+func NewCloseMatchIndex(words map[string]int64, maxDistance int) *CloseMatchIndex {
+	idx := &CloseMatchIndex{
+		maxDistance: maxDistance,
+		words:       make([]string, 0, len(words)),
+		deletes:     make(map[string][]int32),
+		cache:       make(map[string]bool),
+	}
+
+	for word := range words {
+		id := int32(len(idx.words))
+		idx.words = append(idx.words, word)
+		seen := make(map[string]bool)
+		for _, variant := range deletionVariants(word, maxDistance) {
+			if !seen[variant] {
+				seen[variant] = true
+				idx.deletes[variant] = append(idx.deletes[variant], id)
+			}
+		}
+	}
+
+	return idx
+}
+
+// MaxDistance returns the largest distance the index can answer queries for.
+func (idx *CloseMatchIndex) MaxDistance() int {
+	return idx.maxDistance
+}
+
+// Within reports whether any indexed word is within Levenshtein distance k of word. k is capped at the index's
+// maximum distance.
+func (idx *CloseMatchIndex) Within(word string, k int) bool {
+	k = min(k, idx.maxDistance)
+	return idx.search(word, k, func(string) int {
+		return k
+	})
+}
+
+// IsCloseMatch uses the same rule as isCloseMatch: a word is a close match if some dictionary word w is within
+// len(w)/3 edits of it (a 3-letter word may be 1 off, a 6-letter word 2 off). Distances beyond the index's maximum
+// distance are not considered. Results are cached, so repeated queries cost a single map lookup.
+func (idx *CloseMatchIndex) IsCloseMatch(word string) bool {
+	idx.mu.Lock()
+	match, ok := idx.cache[word]
+	idx.mu.Unlock()
+	if ok {
+		return match
+	}
+
+	// A match at distance d needs len(w) >= 3d and len(w) <= len(word)+d, so d can never exceed len(word)/2.
+	match = idx.search(word, min(utf8.RuneCountInString(word)/2, idx.maxDistance), func(w string) int {
+		return min(utf8.RuneCountInString(w)/3, idx.maxDistance)
+	})
+
+	idx.mu.Lock()
+	if len(idx.cache) >= closeMatchCacheSize {
+		idx.cache = make(map[string]bool)
+	}
+	idx.cache[word] = match
+	idx.mu.Unlock()
+
+	return match
+}
+
+// search generates the deletion variants of word up to distance k and reports whether any indexed word w found
+// through them is within limit(w) of word. limit must never exceed k.
+func (idx *CloseMatchIndex) search(word string, k int, limit func(w string) int) bool {
+	if k < 0 {
+		return false
+	}
+
+	length := utf8.RuneCountInString(word)
+	for _, variant := range deletionVariants(word, k) {
+		for _, id := range idx.deletes[variant] {
+			w := idx.words[id]
+			wk := limit(w)
+			if diff := utf8.RuneCountInString(w) - length; diff > wk || -diff > wk {
+				continue
+			}
+			if boundedLevenshteinDistance(word, w, wk) <= wk {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// deletionVariants returns the strings that can be made from word by deleting up to maxDeletes letters, including
+// word itself. Each combination of deleted positions is generated once, so a variant only repeats when word has
+// repeated letters.
+func deletionVariants(word string, maxDeletes int) []string {
+	variants := []string{word}
+	var deleteFrom func(w string, start, deletes int)
+	deleteFrom = func(w string, start, deletes int) {
+		if deletes == 0 {
+			return
+		}
+		sp := newSpelling(w)
+		for i := start; i < sp.len(); i++ {
+			variant := sp.slice(0, i) + sp.slice(i+1, sp.len())
+			variants = append(variants, variant)
+			deleteFrom(variant, i, deletes-1)
+		}
+	}
+	deleteFrom(word, 0, maxDeletes)
+
+	return variants
+}
+
+// boundedLevenshteinDistance is levenshteinDistance for when only distances up to k matter. It keeps two rows instead
+// of the full matrix and gives up with k+1 as soon as every entry in a row is over k.
+func boundedLevenshteinDistance(word1, word2 string, k int) int {
+	a, b := []rune(word1), []rune(word2)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 0
+			if a[i-1] != b[j-1] {
+				cost = 1
+			}
+			cur[j] = min(prev[j]+1, min(cur[j-1]+1, prev[j-1]+cost))
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > k {
+			return k + 1
+		}
+		prev, cur = cur, prev
+	}
+
+	return min(prev[len(b)], k+1)
+}
@@ -0,0 +1,108 @@
+package sifo
+
+import (
+	"testing"
+)
+
+func TestCloseMatchIndexIsCloseMatch(t *testing.T) {
+	words := map[string]int64{
+		"apple":  1,
+		"banana": 1,
+		"cherry": 1,
+		"date":   1,
+		"fig":    1,
+		"grape":  1,
+	}
+
+	idx := NewCloseMatchIndex(words, 2)
+
+	tests := []struct {
+		word     string
+		expected bool
+	}{
+		{"apple", true},   // Exact match
+		{"appl", true},    // One letter off
+		{"apples", true},  // One letter off
+		{"applf", true},   // One letter off
+		{"applz", true},   // One letter off
+		{"banan", true},   // One letter off
+		{"bananas", true}, // One letter off
+		{"bananz", true},  // One letter off
+		{"grap", true},    // One letter off
+		{"grapz", true},   // One letter off
+		{"bonona", true},  // Two letters off, 6 letters
+		{"trope", false},  // Two letters off
+		{"xyz", false},    // No match
+		{"", false},       // Empty string
+	}
+
+	for _, test := range tests {
+		result := idx.IsCloseMatch(test.word)
+		if result != test.expected {
+			t.Errorf("IsCloseMatch(%q) = %v; want %v", test.word, result, test.expected)
+		}
+	}
+}
+
+func TestCloseMatchIndexWithin(t *testing.T) {
+	words := map[string]int64{
+		"warm": 1,
+		"hold": 1,
+	}
+
+	idx := NewCloseMatchIndex(words, 2)
+
+	tests := []struct {
+		word     string
+		k        int
+		expected bool
+	}{
+		{"warm", 0, true},  // Exact match
+		{"worm", 0, false}, // One substitution
+		{"worm", 1, true},  // One substitution
+		{"arm", 1, true},   // One deletion
+		{"swarm", 1, true}, // One insertion
+		{"word", 1, false}, // Two substitutions
+		{"word", 2, true},  // Two substitutions
+		{"bird", 2, false}, // Three substitutions
+		{"bird", 3, false}, // Capped at the index's maximum distance
+	}
+
+	for _, test := range tests {
+		result := idx.Within(test.word, test.k)
+		if result != test.expected {
+			t.Errorf("Within(%q, %d) = %v; want %v", test.word, test.k, result, test.expected)
+		}
+	}
+}
+
+func TestCloseMatchIndexAgreesWithScan(t *testing.T) {
+	words := LoadWords("../words.csv")
+	idx := NewCloseMatchIndex(words, 2)
+	cipher := WarmHoldCipher()
+
+	i := 0
+	for word := range words {
+		if i++; i > 300 {
+			break
+		}
+		encodedWord := encodeWord(word, cipher)
+		if len(encodedWord) > 5 {
+			continue // longer words can match 9+ letter words 3 edits away, beyond the index's maximum distance
+		}
+		if got, want := idx.IsCloseMatch(encodedWord), isCloseMatch(encodedWord, words); got != want {
+			t.Errorf("IsCloseMatch(%q) = %v; isCloseMatch = %v", encodedWord, got, want)
+		}
+	}
+}
+
+func BenchmarkCloseMatchIndexIsCloseMatch(b *testing.B) {
+	words := LoadWords("../words.csv")
+	idx := NewCloseMatchIndex(words, 2)
+
+	for i := 0; i < b.N; i++ {
+		idx.IsCloseMatch("lonely")
+		idx.IsCloseMatch("remark")
+		idx.IsCloseMatch("qzxvj")
+	}
+}
@@ -1,32 +1,91 @@
 package sifo
 
 import (
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// ErrAmbiguousCipher is returned by Inverse and Decode when a cipher's encodings cannot be reversed to a single
+// plain text.
+var ErrAmbiguousCipher = errors.New("cipher cannot be decoded unambiguously")
+
 func Encode(input string, cipher Cipher) string {
+	return English.Encode(input, cipher)
+}
+
+// Encode encodes each whitespace-separated word of input, writing a space after each one.
+func (l Language) Encode(input string, cipher Cipher) string {
 	var encoded strings.Builder
 	for _, word := range strings.Fields(input) {
-		encoded.WriteString(encodeWord(word, cipher))
+		encoded.WriteString(l.encodeWord(word, cipher))
 		encoded.WriteRune(' ')
 	}
 	return encoded.String()
 }
 
+// EncodeChecked encodes input like Encode after checking the cipher with Validate, and returns the validation error
+// instead if the cipher has problems. Use it for ciphers parsed or edited by hand, which are easy to get wrong.
+func EncodeChecked(input string, cipher Cipher) (string, error) {
+	return English.EncodeChecked(input, cipher)
+}
+
+// EncodeChecked validates the cipher over the language's alphabet and encodes input with it.
+func (l Language) EncodeChecked(input string, cipher Cipher) (string, error) {
+	if err := l.check(cipher); err != nil {
+		return "", err
+	}
+	return l.Encode(input, cipher), nil
+}
+
+// check validates the cipher over the language's alphabet for the encoders that report a bad cipher.
+func (l Language) check(cipher Cipher) error {
+	return Validate(cipher, l.orDefault().Alphabet)
+}
+
 func encodeWord(word string, cipher Cipher) string {
+	return English.encodeWord(word, cipher)
+}
+
+// encodeWord encodes a word letter by letter, trying the longest cipher key first. Uppercase letters in the word
+// are matched to lowercase keys and their encodings uppercased with the language's case rules.
+func (l Language) encodeWord(word string, cipher Cipher) string {
+	encoded, _ := l.encodeLetters(word, cipher, 0, 0)
+	return encoded
+}
+
+// encodeLetters encodes the start of a run of letters, stopping once fewer than lookahead letters are left, and
+// returns the encoding and how many bytes of word it covers. The longest match and the case of each encoded letter
+// depend on the letters around it, so a lookahead of more than twice the longest key of the cipher encodes the start
+// of a run exactly as if the rest of it were known, and leaves enough of it for the rest to be encoded exactly too,
+// the letter before its last match included. A lookahead of 0 encodes all of word. Matches are tried
+// from keyLength letters down, or from the rest of word when keyLength is 0, which keeps long runs from taking time
+// quadratic in their length.
+func (l Language) encodeLetters(word string, cipher Cipher, keyLength, lookahead int) (string, int) {
 	var encoded strings.Builder
+	sp := newSpelling(word)
 	i := 0
-	for i < len(word) {
+	for i < sp.len() && i+lookahead <= sp.len() {
 		matched := false
-		for length := len(word) - i; length > 0; length-- {
-			substr := word[i : i+length]
-			if encodedChars, ok := cipher[strings.ToLower(substr)]; ok {
-				for j, char := range encodedChars {
-					if i+j < len(word) && isUpper(word[i+j]) {
-						encoded.WriteRune(toUpper(char))
+		length := sp.len() - i
+		if keyLength > 0 {
+			length = min(length, keyLength)
+		}
+		for ; length > 0; length-- {
+			substr := sp.slice(i, i+length)
+			if encodedChars, ok := cipher[l.ToLower(substr)]; ok {
+				m := utf8.RuneCountInString(encodedChars)
+				j := 0
+				for _, char := range encodedChars {
+					if encodedUpper(sp, i, length, j, m) {
+						encoded.WriteRune(l.ToUpperRune(char))
 					} else {
 						encoded.WriteRune(char)
 					}
+					j++
 				}
 				i += length
 				matched = true
@@ -34,26 +93,47 @@ func encodeWord(word string, cipher Cipher) string {
 			}
 		}
 		if !matched {
-			if isUpper(word[i]) {
-				encoded.WriteRune(toUpper(rune(word[i])))
-			} else {
-				encoded.WriteRune(rune(word[i]))
-			}
+			encoded.WriteString(sp.slice(i, i+1))
 			i++
 		}
 	}
-	return encoded.String()
+	return encoded.String(), len(sp.slice(0, i))
 }
 
-func isUpper(r byte) bool {
-	return r >= 'A' && r <= 'Z'
-}
+// encodedUpper reports whether the jth of the m letters encoding the length letters of sp at i is upper case. Each
+// encoded letter takes the case of its source letter when the two have the same length. Otherwise the case pattern
+// of the source carries over: an all capital source encodes in capitals, a capitalized one is capitalized, and a
+// mixed one spreads its cases over the encoding. A single capital letter stands for a capitalized word, as the "T"
+// in "The", unless the letter after it, or before it at the end of a word, is also a capital, as in "THE" or "AT".
+func encodedUpper(sp spelling, i, length, j, m int) bool {
+	if m == length {
+		return unicode.IsUpper(sp.at(i + j))
+	}
+
+	allUpper, restLower := true, true
+	for k := 0; k < length; k++ {
+		if !unicode.IsUpper(sp.at(i + k)) {
+			allUpper = false
+		} else if k > 0 {
+			restLower = false
+		}
+	}
 
-func toUpper(r rune) rune {
-	if r >= 'a' && r <= 'z' {
-		return r - 'a' + 'A'
+	switch {
+	case allUpper && (length > 1 || j == 0):
+		return true
+	case allUpper:
+		if i+1 < sp.len() {
+			return unicode.IsUpper(sp.at(i + 1))
+		}
+		return i > 0 && unicode.IsUpper(sp.at(i-1))
+	case j == 0:
+		return unicode.IsUpper(sp.at(i))
+	case restLower:
+		return false
+	default:
+		return unicode.IsUpper(sp.at(i + j*length/m))
 	}
-	return r
 }
 
 // decodeWord decodes a word using the given cipher from the end of the word to the beginning. In attempting to use
@@ -96,3 +176,133 @@ func decodeWord2(encodedWord string, cipher Cipher) string {
 
 	return encodeWord(encodedWord, reverseCipher)
 }
+
+// Inverse returns the cipher that decodes what cipher encodes. It returns an error wrapping ErrAmbiguousCipher if
+// an encoding could have come from more than one plain text:
+//   - two keys encode to the same value;
+//   - one value is a prefix of another, so a run of encoded letters splits into values more than one way;
+//   - a value contains a letter that is not itself a key, so that letter could equally have been plain text passed
+//     through unchanged.
+func Inverse(cipher Cipher) (Cipher, error) {
+	keys := make([]string, 0, len(cipher))
+	for key := range cipher {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	inverse := make(Cipher, len(cipher))
+	for _, key := range keys {
+		value := cipher[key]
+		if key == "" || value == "" {
+			return nil, fmt.Errorf("%w: %q encodes to %q", ErrAmbiguousCipher, key, value)
+		}
+		if other, ok := inverse[value]; ok {
+			return nil, fmt.Errorf("%w: %q and %q both encode to %q", ErrAmbiguousCipher, other, key, value)
+		}
+		inverse[value] = key
+	}
+
+	values := make([]string, 0, len(inverse))
+	for value := range inverse {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	// After sorting, a value that is a prefix of others comes right before the first of them.
+	for i := 1; i < len(values); i++ {
+		if strings.HasPrefix(values[i], values[i-1]) {
+			return nil, fmt.Errorf("%w: %q is a prefix of %q", ErrAmbiguousCipher, values[i-1], values[i])
+		}
+	}
+
+	for _, value := range values {
+		for _, r := range value {
+			if _, ok := cipher[string(unicode.ToLower(r))]; !ok && unicode.IsLetter(r) {
+				return nil, fmt.Errorf("%w: %q in %q is not encoded, so it could also be plain text", ErrAmbiguousCipher, string(r), value)
+			}
+		}
+	}
+
+	return inverse, nil
+}
+
+// Decode reverses Encode: it returns the plain text that encodes to text. Unlike Encode, it keeps the text's
+// whitespace and punctuation as they are. It returns an error wrapping ErrAmbiguousCipher if the cipher cannot be
+// decoded unambiguously (see Inverse).
+func Decode(text string, cipher Cipher) (string, error) {
+	return English.Decode(text, cipher)
+}
+
+// Decode decodes text by encoding it with the cipher's inverse.
+func (l Language) Decode(text string, cipher Cipher) (string, error) {
+	inverse, err := Inverse(cipher)
+	if err != nil {
+		return "", err
+	}
+	return l.encodeRuns(text, inverse), nil
+}
+
+// EncodeText encodes text like Encode but keeps everything that is not a letter exactly as it is: whitespace, line
+// breaks, digits and punctuation, including the apostrophe in "Here's" and the hyphen in "well-known". Decode
+// reverses it exactly.
+func EncodeText(text string, cipher Cipher) string {
+	return English.EncodeText(text, cipher)
+}
+
+// EncodeText encodes the runs of letters in text and copies everything between them.
+func (l Language) EncodeText(text string, cipher Cipher) string {
+	return l.encodeRuns(text, cipher)
+}
+
+// EncodeTextChecked encodes text like EncodeText after checking the cipher with Validate, and returns the validation
+// error instead if the cipher has problems.
+func EncodeTextChecked(text string, cipher Cipher) (string, error) {
+	return English.EncodeTextChecked(text, cipher)
+}
+
+// EncodeTextChecked validates the cipher over the language's alphabet and encodes text with it.
+func (l Language) EncodeTextChecked(text string, cipher Cipher) (string, error) {
+	if err := l.check(cipher); err != nil {
+		return "", err
+	}
+	return l.EncodeText(text, cipher), nil
+}
+
+// encodeRuns splits text into runs of letters and everything else, and encodes only the runs of letters, so that the
+// longest match never spans whitespace or punctuation. The bytes between runs, even invalid UTF-8, are copied as
+// they are.
+func (l Language) encodeRuns(text string, cipher Cipher) string {
+	keyLength := longestKey(cipher)
+	var encoded strings.Builder
+	for len(text) > 0 {
+		i := strings.IndexFunc(text, unicode.IsLetter)
+		if i < 0 {
+			encoded.WriteString(text)
+			break
+		}
+		encoded.WriteString(text[:i])
+		text = text[i:]
+
+		j := strings.IndexFunc(text, func(r rune) bool {
+			return !unicode.IsLetter(r)
+		})
+		if j < 0 {
+			j = len(text)
+		}
+		word, _ := l.encodeLetters(text[:j], cipher, keyLength, 0)
+		encoded.WriteString(word)
+		text = text[j:]
+	}
+	return encoded.String()
+}
+
+// longestKey returns the number of letters in the longest key of the cipher.
+func longestKey(cipher Cipher) int {
+	longest := 0
+	for key := range cipher {
+		if n := utf8.RuneCountInString(key); n > longest {
+			longest = n
+		}
+	}
+	return longest
+}
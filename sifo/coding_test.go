@@ -1,7 +1,10 @@
 package sifo
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"unicode"
 )
 
 func TestEncodeWord(t *testing.T) {
@@ -123,3 +126,161 @@ func TestEncodeDecodeWordSimple(t *testing.T) {
 		}
 	}
 }
+
+func TestInverse(t *testing.T) {
+	inverse, err := Inverse(WarmHoldCipher())
+	if err != nil {
+		t.Fatalf("Inverse(WarmHoldCipher()) error = %v", err)
+	}
+	for key, value := range WarmHoldCipher() {
+		if inverse[value] != key {
+			t.Errorf("Inverse(WarmHoldCipher())[%q] = %q; want %q", value, inverse[value], key)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		cipher Cipher
+		want   string
+	}{
+		{"duplicate value", Cipher{"a": "b", "b": "b"}, `"a" and "b" both encode to "b"`},
+		{"prefix", Cipher{"a": "b", "b": "ba"}, `"b" is a prefix of "ba"`},
+		{"pass-through", Cipher{"a": "x"}, `"x" in "x" is not encoded`},
+		{"empty value", Cipher{"a": ""}, `"a" encodes to ""`},
+	}
+
+	for _, test := range tests {
+		_, err := Inverse(test.cipher)
+		if !errors.Is(err, ErrAmbiguousCipher) || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Inverse() with a %s error = %v; want ErrAmbiguousCipher containing %q", test.name, err, test.want)
+		}
+	}
+}
+
+func TestEncodeWordCase(t *testing.T) {
+	// Keys and values of different lengths keep the case pattern of the source letters.
+	cipher := Cipher{"a": "b", "b": "ca", "c": "cb", "th": "a", "t": "cc", "h": "cd", "d": "ce", "e": "d", "é": "ee"}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"the", "ad"},
+		{"The", "Ad"},
+		{"THE", "AD"},
+		{"Tea", "Ccdb"},
+		{"TEA", "CCDB"},
+		{"AT", "BCC"},
+		{"At", "Bcc"},
+		{"BeD", "CadCe"},
+		{"Été", "Eeccee"},
+		{"ÉTÉ", "EECCEE"},
+		{"DéBâCLE", "CeeeCaâCBLD"},
+	}
+
+	for _, test := range tests {
+		if result := encodeWord(test.input, cipher); result != test.expected {
+			t.Errorf("encodeWord(%q) = %q; want %q", test.input, result, test.expected)
+		}
+	}
+
+	// Decoding restores the case of every word whose case is not ambiguous.
+	text := "THE DEATH OF Teeth, AT the bath. Bathed ÉTÉ Été"
+	decoded, err := Decode(EncodeText(text, cipher), cipher)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if decoded != text {
+		t.Errorf("Decode(EncodeText(%q)) = %q", text, decoded)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, cipher := range []Cipher{LonelyRemarkCipher(), WarmHoldCipher(), WormHelpCipher(), MoonPeerCipher(), WormHeldCipher()} {
+		encoded := Encode(quote, cipher)
+		decoded, err := Decode(encoded, cipher)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if want := quote + " "; decoded != want {
+			t.Errorf("Decode(Encode(quote)) = %q; want %q", decoded, want)
+		}
+	}
+
+	text := "Here's to\tthe crazy ones.\n\nThe misfits!"
+	decoded, err := Decode(EncodeText(text, WarmHoldCipher()), WarmHoldCipher())
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if decoded != text {
+		t.Errorf("Decode() = %q; want the whitespace and punctuation of %q kept", decoded, text)
+	}
+}
+
+func TestDecodeMultiCharacter(t *testing.T) {
+	// Prefix-free values round-trip even when keys and values differ in length.
+	cipher := Cipher{"a": "b", "b": "ca", "c": "cb", "th": "a", "t": "cc", "h": "cd", "d": "ce", "e": "d"}
+	words := []string{"the", "bath", "cat", "death", "Teeth"}
+
+	for _, word := range words {
+		encoded := encodeWord(word, cipher)
+		decoded, err := Decode(encoded, cipher)
+		if err != nil {
+			t.Fatalf("Decode(%q) error = %v", encoded, err)
+		}
+		if decoded != word {
+			t.Errorf("Decode(%q) = %q; want %q", encoded, decoded, word)
+		}
+	}
+
+	if _, err := Decode("anything", Cipher{"a": "x", "b": "xy"}); !errors.Is(err, ErrAmbiguousCipher) {
+		t.Errorf("Decode() with an ambiguous cipher error = %v; want ErrAmbiguousCipher", err)
+	}
+}
+
+func TestEncodeText(t *testing.T) {
+	cipher := WarmHoldCipher()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"warm", "hold"},
+		{"Here's", "Yala't"},
+		{"  warm\t\twarm  \n", "  hold\t\thold  \n"},
+		{"well-known 42", "harr-vmehm 42"},
+		{"WARM\r\nWarm", "HOLD\r\nHold"},
+		{"warm\xffwarm", "hold\xffhold"}, // invalid UTF-8 is copied as is
+	}
+
+	for _, test := range tests {
+		if result := EncodeText(test.input, cipher); result != test.expected {
+			t.Errorf("EncodeText(%q) = %q; want %q", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestEncodeTextMarkdown(t *testing.T) {
+	doc := "# Results\n\n| Word | Encoded |\n| --- | --- |\n| warm | hold |\n\n- [link](https://example.com/a_b?c=1)\n  * nested, item's text\n\n```\ncode  block\n```\n"
+	cipher := WarmHoldCipher()
+
+	encoded := EncodeText(doc, cipher)
+	if len(encoded) != len(doc) {
+		t.Fatalf("EncodeText() changed the length of a one-letter cipher's output: %d bytes, want %d", len(encoded), len(doc))
+	}
+	for i := 0; i < len(doc); i++ {
+		isLetter := unicode.IsLetter(rune(doc[i]))
+		if !isLetter && encoded[i] != doc[i] {
+			t.Errorf("EncodeText() changed byte %d from %q to %q", i, doc[i], encoded[i])
+		}
+	}
+
+	decoded, err := Decode(encoded, cipher)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if decoded != doc {
+		t.Errorf("Decode(EncodeText(doc)) = %q; want %q", decoded, doc)
+	}
+}
@@ -0,0 +1,203 @@
+package sifo
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// compiledVersion is part of every compiled dictionary key. Bump it whenever the feature builders or the overlay
+// change what they produce, so that compiled dictionaries built by older code are rebuilt rather than trusted.
+//
+//	1: first compiled format
+//	2: features built from counts, table-built feature sets, and overlay exclusions and weights applied to the words
+const compiledVersion = 2
+
+// ErrStaleDictionary is returned by LoadDictionary when the compiled dictionary was built from a different word file,
+// language or builder version than the key asks for.
+var ErrStaleDictionary = errors.New("compiled dictionary is stale")
+
+// compiledDictionary is the on-disk form of a Dictionary: the words and the twelve feature sets built from them.
+// The optional scoring components are cheap to build or depend on other files, so they are not stored.
+type compiledDictionary struct {
+	Key                      string
+	Words                    map[string]int64
+	Prefixes                 map[string]bool
+	Suffixes                 map[string]bool
+	Middles                  map[string]bool
+	AntiPrefixes             map[string]bool
+	AntiSuffixes             map[string]bool
+	AntiMiddles              map[string]bool
+	WordPatterns             map[string]bool
+	VowelGroups              map[string]bool
+	ConsonantGroups          map[string]bool
+	VowelConsonantBoundaries map[string]bool
+	ConsonantVowelBoundaries map[string]bool
+}
+
+// DictionaryKey identifies the compiled dictionary built from a word file in a language. It is a hash of the file's
+// contents and those of its overlay file, the format it is read as, the language's alphabet, vowels and case rules,
+// and the builder version, so it changes whenever any of them does.
+func DictionaryKey(wordsFile string, lang Language) (string, error) {
+	file, err := os.Open(wordsFile)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+
+	if overlay, err := os.Open(OverlayFilename(wordsFile)); err == nil {
+		fmt.Fprint(h, "\x00overlay=")
+		_, err = io.Copy(h, overlay)
+		overlay.Close()
+		if err != nil {
+			return "", err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	lang = lang.orDefault()
+	fmt.Fprintf(h, "\x00version=%d\x00format=%s\x00alphabet=%s\x00vowels=%s\x00case=%v", compiledVersion,
+		FormatFromFilename(wordsFile), string(lang.Alphabet), string(lang.Vowels), lang.Case)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// SaveDictionary writes the dictionary's words and feature sets to a file under key.
+func SaveDictionary(filename string, dict Dictionary, key string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteDictionary(file, dict, key); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// WriteDictionary gob-encodes the dictionary's words and feature sets under key.
+func WriteDictionary(w io.Writer, dict Dictionary, key string) error {
+	bw := bufio.NewWriter(w)
+	err := gob.NewEncoder(bw).Encode(compiledDictionary{
+		Key:                      key,
+		Words:                    dict.Words,
+		Prefixes:                 dict.Prefixes,
+		Suffixes:                 dict.Suffixes,
+		Middles:                  dict.Middles,
+		AntiPrefixes:             dict.AntiPrefixes,
+		AntiSuffixes:             dict.AntiSuffixes,
+		AntiMiddles:              dict.AntiMiddles,
+		WordPatterns:             dict.WordPatterns,
+		VowelGroups:              dict.VowelGroups,
+		ConsonantGroups:          dict.ConsonantGroups,
+		VowelConsonantBoundaries: dict.VowelConsonantBoundaries,
+		ConsonantVowelBoundaries: dict.ConsonantVowelBoundaries,
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// LoadDictionary reads a compiled dictionary from a file. It returns ErrStaleDictionary if the file was saved under a
+// different key.
+func LoadDictionary(filename string, key string) (Dictionary, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return Dictionary{}, err
+	}
+	defer file.Close()
+
+	return ReadDictionary(file, key)
+}
+
+// ReadDictionary decodes a compiled dictionary written by WriteDictionary. It returns ErrStaleDictionary if the
+// dictionary was written under a different key. The returned dictionary's Language is left as the zero value; set it
+// to the language the key was made with.
+func ReadDictionary(r io.Reader, key string) (Dictionary, error) {
+	var compiled compiledDictionary
+	if err := gob.NewDecoder(bufio.NewReader(r)).Decode(&compiled); err != nil {
+		return Dictionary{}, err
+	}
+	if compiled.Key != key {
+		return Dictionary{}, ErrStaleDictionary
+	}
+
+	return Dictionary{
+		Words:                    compiled.Words,
+		Prefixes:                 compiled.Prefixes,
+		Suffixes:                 compiled.Suffixes,
+		Middles:                  compiled.Middles,
+		AntiPrefixes:             compiled.AntiPrefixes,
+		AntiSuffixes:             compiled.AntiSuffixes,
+		AntiMiddles:              compiled.AntiMiddles,
+		WordPatterns:             compiled.WordPatterns,
+		VowelGroups:              compiled.VowelGroups,
+		ConsonantGroups:          compiled.ConsonantGroups,
+		VowelConsonantBoundaries: compiled.VowelConsonantBoundaries,
+		ConsonantVowelBoundaries: compiled.ConsonantVowelBoundaries,
+	}, nil
+}
+
+// LoadOrBuildDictionary returns the dictionary compiled from wordsFile in lang, curated by the word file's overlay if
+// it has one. It uses the compiled dictionary in cacheFile when that was built from the same word file, language and
+// builder version. Otherwise it loads the words, builds the dictionary and saves it to cacheFile for next time. A
+// cache that cannot be written is reported but is not an error, since the dictionary itself was built.
+func LoadOrBuildDictionary(wordsFile, cacheFile string, lang Language) (Dictionary, error) {
+	lang = lang.orDefault()
+
+	key, err := DictionaryKey(wordsFile, lang)
+	if err != nil {
+		return Dictionary{}, err
+	}
+
+	overlay, err := loadOverlayFor(wordsFile)
+	if err != nil {
+		return Dictionary{}, err
+	}
+
+	dict, err := LoadDictionary(cacheFile, key)
+	if err == nil {
+		dict.Language = lang
+		dict.Overlay = overlay
+		fmt.Printf("Loaded compiled dictionary %s (%d words)\n", cacheFile, len(dict.Words))
+		return dict, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		fmt.Printf("Rebuilding compiled dictionary %s: %v\n", cacheFile, err)
+	}
+
+	file, err := os.Open(wordsFile)
+	if err != nil {
+		return Dictionary{}, err
+	}
+	defer file.Close()
+
+	words, err := ReadWords(file, ReadOptions{Format: FormatFromFilename(wordsFile), Overlay: overlay})
+	if err != nil {
+		return Dictionary{}, fmt.Errorf("%s: %w", wordsFile, err)
+	}
+	fmt.Printf("Loaded %d words\n", len(words))
+
+	dict = NewDictionary(words, lang)
+	dict.Overlay = overlay
+	if err := SaveDictionary(cacheFile, dict, key); err != nil {
+		fmt.Printf("Could not save compiled dictionary %s: %v\n", cacheFile, err)
+	}
+
+	return dict, nil
+}
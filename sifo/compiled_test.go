@@ -0,0 +1,120 @@
+package sifo
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unicode"
+)
+
+func TestWriteReadDictionary(t *testing.T) {
+	words := map[string]int64{"street": 10, "banana": 5, "apple": 3}
+	dict := NewDictionary(words, English)
+
+	var buf bytes.Buffer
+	if err := WriteDictionary(&buf, dict, "key"); err != nil {
+		t.Fatalf("WriteDictionary() error = %v", err)
+	}
+
+	got, err := ReadDictionary(bytes.NewReader(buf.Bytes()), "key")
+	if err != nil {
+		t.Fatalf("ReadDictionary() error = %v", err)
+	}
+	got.Language = English
+	if !reflect.DeepEqual(got, dict) {
+		t.Errorf("ReadDictionary() did not return the dictionary written")
+	}
+
+	if _, err := ReadDictionary(bytes.NewReader(buf.Bytes()), "other"); !errors.Is(err, ErrStaleDictionary) {
+		t.Errorf("ReadDictionary() with a different key error = %v; want %v", err, ErrStaleDictionary)
+	}
+}
+
+func TestDictionaryKey(t *testing.T) {
+	dir := t.TempDir()
+	wordsFile := filepath.Join(dir, "words.csv")
+	writeFile(t, wordsFile, "Word,Count Per Billion\nstreet,10\n")
+
+	key, err := DictionaryKey(wordsFile, English)
+	if err != nil {
+		t.Fatalf("DictionaryKey() error = %v", err)
+	}
+	if again, _ := DictionaryKey(wordsFile, Language{}); again != key {
+		t.Errorf("DictionaryKey() with the zero Language = %s; want the English key %s", again, key)
+	}
+	if spanish, _ := DictionaryKey(wordsFile, Spanish); spanish == key {
+		t.Errorf("DictionaryKey() is the same for English and Spanish")
+	}
+	turkishCase := English
+	turkishCase.Case = unicode.TurkishCase
+	if cased, _ := DictionaryKey(wordsFile, turkishCase); cased == key {
+		t.Errorf("DictionaryKey() is the same with and without Turkish case rules")
+	}
+
+	writeFile(t, wordsFile, "Word,Count Per Billion\nstreet,11\n")
+	if changed, _ := DictionaryKey(wordsFile, English); changed == key {
+		t.Errorf("DictionaryKey() did not change when the word file did")
+	}
+
+	if _, err := DictionaryKey(filepath.Join(dir, "missing.csv"), English); err == nil {
+		t.Errorf("DictionaryKey() of a missing file error = nil; want an error")
+	}
+}
+
+func TestLoadOrBuildDictionary(t *testing.T) {
+	dir := t.TempDir()
+	wordsFile := filepath.Join(dir, "words.csv")
+	cacheFile := filepath.Join(dir, "words.dict")
+	writeFile(t, wordsFile, "Word,Count Per Billion\nstreet,10\nbanana,5\n")
+
+	built, err := LoadOrBuildDictionary(wordsFile, cacheFile, English)
+	if err != nil {
+		t.Fatalf("LoadOrBuildDictionary() error = %v", err)
+	}
+	if _, err := os.Stat(cacheFile); err != nil {
+		t.Fatalf("LoadOrBuildDictionary() did not save the compiled dictionary: %v", err)
+	}
+
+	cached, err := LoadOrBuildDictionary(wordsFile, cacheFile, English)
+	if err != nil {
+		t.Fatalf("LoadOrBuildDictionary() from the cache error = %v", err)
+	}
+	if !reflect.DeepEqual(cached, built) {
+		t.Errorf("LoadOrBuildDictionary() from the cache differs from the dictionary built")
+	}
+
+	// Changing the source invalidates the cache.
+	writeFile(t, wordsFile, "Word,Count Per Billion\nstreet,10\nbanana,5\napple,3\n")
+	rebuilt, err := LoadOrBuildDictionary(wordsFile, cacheFile, English)
+	if err != nil {
+		t.Fatalf("LoadOrBuildDictionary() after a change error = %v", err)
+	}
+	if len(rebuilt.Words) != 3 {
+		t.Errorf("LoadOrBuildDictionary() after a change has %d words; want 3", len(rebuilt.Words))
+	}
+
+	// So does changing the language.
+	spanish, err := LoadOrBuildDictionary(wordsFile, cacheFile, Spanish)
+	if err != nil {
+		t.Fatalf("LoadOrBuildDictionary() in Spanish error = %v", err)
+	}
+	if spanish.Language.Name != "Spanish" || len(spanish.AntiPrefixes) == len(rebuilt.AntiPrefixes) {
+		t.Errorf("LoadOrBuildDictionary() in Spanish reused the English dictionary")
+	}
+
+	// A corrupt cache is rebuilt rather than returned as an error.
+	writeFile(t, cacheFile, "not a dictionary")
+	if _, err := LoadOrBuildDictionary(wordsFile, cacheFile, English); err != nil {
+		t.Errorf("LoadOrBuildDictionary() with a corrupt cache error = %v", err)
+	}
+}
+
+func writeFile(t *testing.T, filename, content string) {
+	t.Helper()
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,237 @@
+package sifo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+)
+
+const (
+	// bigramWeight is the score given to an encoded token pair the reference model finds fully plausible, before
+	// weighting by how often the pair occurs in the corpus.
+	bigramWeight = 5.0
+
+	// bigramLambda is how much of a bigram's probability comes from the pair itself rather than from how common the
+	// second word is on its own.
+	bigramLambda = 0.7
+)
+
+// Corpus is a body of text the search can optimize encodings for, such as a genre of prose rather than the
+// dictionary's isolated headwords. Tokens are lowercase letter runs, weighted by how often they appear in the text.
+// Bigrams are adjacent token pairs within a sentence.
+type Corpus struct {
+	Tokens  map[string]int64
+	Bigrams map[[2]string]int64
+	Model   *BigramModel // optional; nil disables bigram plausibility scoring
+}
+
+// LoadCorpus reads a corpus from a text file.
+func LoadCorpus(filename string) (*Corpus, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadCorpus(file)
+}
+
+// ReadCorpus tokenizes text and counts its tokens and the token pairs within each sentence.
+func ReadCorpus(r io.Reader) (*Corpus, error) {
+	corpus := &Corpus{
+		Tokens:  make(map[string]int64),
+		Bigrams: make(map[[2]string]int64),
+	}
+
+	err := readSentences(r, func(sentence []string) {
+		for j, token := range sentence {
+			corpus.Tokens[token]++
+			if j > 0 {
+				corpus.Bigrams[[2]string{sentence[j-1], token}]++
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return corpus, nil
+}
+
+// BigramModel is a word-bigram language model trained on a reference corpus. It says how plausible it is for one
+// word to follow another, so that an encoded phrase can be judged on whether it reads naturally rather than only on
+// whether its words exist.
+type BigramModel struct {
+	unigrams map[string]int64
+	bigrams  map[[2]string]int64
+	total    int64
+}
+
+// LoadBigramModel trains a bigram model on a text file.
+func LoadBigramModel(filename string) (*BigramModel, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadBigramModel(file)
+}
+
+// ReadBigramModel trains a bigram model on text.
+func ReadBigramModel(r io.Reader) (*BigramModel, error) {
+	model := &BigramModel{
+		unigrams: make(map[string]int64),
+		bigrams:  make(map[[2]string]int64),
+	}
+
+	err := readSentences(r, func(sentence []string) {
+		for j, token := range sentence {
+			model.unigrams[token]++
+			model.total++
+			if j > 0 {
+				model.bigrams[[2]string{sentence[j-1], token}]++
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// LogProb returns the log probability of word following prev. The bigram estimate is interpolated with an add-one
+// smoothed unigram estimate, so unseen pairs and unseen words still get a small, nonzero probability.
+func (m *BigramModel) LogProb(prev, word string) float64 {
+	unigram := float64(m.unigrams[word]+1) / float64(m.total+int64(len(m.unigrams))+1)
+
+	prevCount := m.unigrams[prev]
+	if prevCount == 0 {
+		return math.Log(unigram)
+	}
+
+	bigram := float64(m.bigrams[[2]string{prev, word}]) / float64(prevCount)
+	return math.Log(bigramLambda*bigram + (1-bigramLambda)*unigram)
+}
+
+// Plausibility maps LogProb onto 0 to 1, where 0 is the probability of an unseen word after a known one and 1 is a
+// certainty.
+func (m *BigramModel) Plausibility(prev, word string) float64 {
+	floor := math.Log((1 - bigramLambda) / float64(m.total+int64(len(m.unigrams))+1))
+	p := (m.LogProb(prev, word) - floor) / -floor
+	return math.Min(math.Max(p, 0), 1)
+}
+
+// ScoreCorpus scores a cipher against a corpus the way Score does against the dictionary: each token is encoded and
+// scores as a word if it encodes to one and by its English patterns otherwise, weighted by its occurrence score in
+// the corpus. When the corpus has a model, each encoded token pair also scores by its plausibility.
+func ScoreCorpus(dict Dictionary, corpus *Corpus, cipher Cipher, output bool) float64 {
+	var score float64
+	lang := dict.Language.orDefault()
+
+	tokenTotal := sumCounts(corpus.Tokens)
+	for token, count := range corpus.Tokens {
+		occurrence := occurrenceScore(perBillion(count, tokenTotal))
+		encodedToken := lang.encodeWord(token, cipher)
+		if encOccurence, ok := dict.Words[encodedToken]; ok {
+			s := 10 * max(occurrence, occurrenceScore(encOccurence))
+			score = score + s
+
+			if output {
+				fmt.Printf("corpus: %s -> %s (%d occurrences, score %.4f)\n", token, encodedToken, count, s)
+			}
+			continue
+		}
+
+		score = score + adjustEPC(englishPattern(encodedToken, dict))*occurrence
+	}
+
+	if corpus.Model != nil {
+		bigramTotal := sumCounts(corpus.Bigrams)
+		for pair, count := range corpus.Bigrams {
+			prev, word := lang.encodeWord(pair[0], cipher), lang.encodeWord(pair[1], cipher)
+			plausibility := corpus.Model.Plausibility(prev, word)
+			s := bigramWeight * plausibility * occurrenceScore(perBillion(count, bigramTotal))
+			score = score + s
+
+			if output && plausibility > 0.5 {
+				fmt.Printf("corpus: %s %s -> %s %s (plausibility %.4f, score %.4f)\n", pair[0], pair[1], prev, word, plausibility, s)
+			}
+		}
+	}
+
+	if output {
+		fmt.Printf("Corpus score: %.4f\n", score)
+	}
+	return score
+}
+
+// perBillion scales a count out of total to the "Count Per Billion" units of words.csv, so that occurrenceScore
+// applies to corpus counts too.
+func perBillion(count, total int64) int64 {
+	if total == 0 {
+		return 0
+	}
+	return int64(float64(count) / float64(total) * 1e9)
+}
+
+func sumCounts[K comparable](counts map[K]int64) int64 {
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
+
+// Tokenize splits text into lowercase letter runs. Everything else, including apostrophes and hyphens, separates
+// tokens, so "Here's" is "here" and "s".
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// readSentences calls fn with the tokens of each sentence in the text. Sentences end at ".", "!", "?" and blank
+// lines.
+func readSentences(r io.Reader, fn func(sentence []string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var sentence []string
+	flush := func() {
+		if len(sentence) > 0 {
+			fn(sentence)
+			sentence = nil
+		}
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+
+		for {
+			end := strings.IndexAny(line, ".!?")
+			if end < 0 {
+				sentence = append(sentence, Tokenize(line)...)
+				break
+			}
+			sentence = append(sentence, Tokenize(line[:end])...)
+			flush()
+			line = line[end+1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	flush()
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package sifo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"Here's to the crazy ones.", []string{"here", "s", "to", "the", "crazy", "ones"}},
+		{"trouble-makers", []string{"trouble", "makers"}},
+		{"  Warm\tHOLD\n", []string{"warm", "hold"}},
+		{"123 ...", []string{}},
+		{"", []string{}},
+	}
+
+	for _, test := range tests {
+		result := Tokenize(test.text)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("Tokenize(%q) = %v; want %v", test.text, result, test.expected)
+		}
+	}
+}
+
+func TestReadCorpus(t *testing.T) {
+	corpus, err := ReadCorpus(strings.NewReader("The misfits. The rebels.\n\nThe round pegs\nin the square holes."))
+	if err != nil {
+		t.Fatalf("ReadCorpus() error = %v", err)
+	}
+
+	if got := corpus.Tokens["the"]; got != 4 {
+		t.Errorf("Tokens[%q] = %d; want 4", "the", got)
+	}
+	if got := corpus.Bigrams[[2]string{"the", "misfits"}]; got != 1 {
+		t.Errorf("Bigrams[the misfits] = %d; want 1", got)
+	}
+	if got := corpus.Bigrams[[2]string{"misfits", "the"}]; got != 0 {
+		t.Errorf("Bigrams[misfits the] = %d; want 0 (pairs do not cross sentences)", got)
+	}
+	if got := corpus.Bigrams[[2]string{"pegs", "in"}]; got != 1 {
+		t.Errorf("Bigrams[pegs in] = %d; want 1 (sentences continue across lines)", got)
+	}
+}
+
+func TestBigramModelPlausibility(t *testing.T) {
+	model, err := ReadBigramModel(strings.NewReader("The cat sat. The dog sat. A cat ran."))
+	if err != nil {
+		t.Fatalf("ReadBigramModel() error = %v", err)
+	}
+
+	tests := []struct {
+		more, less [2]string
+	}{
+		{[2]string{"the", "cat"}, [2]string{"cat", "the"}}, // Seen pair beats unseen pair
+		{[2]string{"cat", "sat"}, [2]string{"cat", "dog"}}, // Seen pair beats unseen pair
+		{[2]string{"dog", "cat"}, [2]string{"dog", "zzz"}}, // Known word beats unknown word
+	}
+
+	for _, test := range tests {
+		more := model.Plausibility(test.more[0], test.more[1])
+		less := model.Plausibility(test.less[0], test.less[1])
+		if more <= less {
+			t.Errorf("Plausibility(%v) = %.4f; want more than Plausibility(%v) = %.4f", test.more, more, test.less, less)
+		}
+		if more < 0 || more > 1 || less < 0 || less > 1 {
+			t.Errorf("Plausibility(%v), Plausibility(%v) = %.4f, %.4f; want between 0 and 1", test.more, test.less, more, less)
+		}
+	}
+}
+
+func TestScoreCorpus(t *testing.T) {
+	dict := Dictionary{
+		Words: map[string]int64{
+			"warm": 77220,
+			"hold": 186609,
+		},
+	}
+
+	corpus, err := ReadCorpus(strings.NewReader("Warm hands."))
+	if err != nil {
+		t.Fatalf("ReadCorpus() error = %v", err)
+	}
+
+	// "warm" is half of the corpus, so its occurrence score is the top one, and it encodes to "hold". "hands" encodes
+	// to "yomnt", which has no English patterns in an empty dictionary.
+	if got, want := ScoreCorpus(dict, corpus, WarmHoldCipher(), false), 10*occurrenceScore(500000000); got != want {
+		t.Errorf("ScoreCorpus() = %.4f; want %.4f", got, want)
+	}
+
+	corpus.Model, err = ReadBigramModel(strings.NewReader("Hold yomnt."))
+	if err != nil {
+		t.Fatalf("ReadBigramModel() error = %v", err)
+	}
+	if got, want := ScoreCorpus(dict, corpus, WarmHoldCipher(), false), 10*occurrenceScore(500000000); got <= want {
+		t.Errorf("ScoreCorpus() with model = %.4f; want more than %.4f", got, want)
+	}
+}
+
+func TestScoreCorpusLanguage(t *testing.T) {
+	turkish := Language{
+		Name:     "Turkish",
+		Alphabet: []rune("abcçdefgğhıijklmnoöprsştuüvyz"),
+		Vowels:   []rune("aeıioöuü"),
+		Case:     unicode.TurkishCase,
+	}
+	dict := Dictionary{Words: map[string]int64{"İl": 1000}, Language: turkish}
+	corpus := &Corpus{Tokens: map[string]int64{"Il": 1}}
+
+	// Under Turkish case rules "I" is the capital of "ı", which encodes to "i" and is capitalized as "İ".
+	if got, want := ScoreCorpus(dict, corpus, Cipher{"i": "ı", "ı": "i"}, false), 10*occurrenceScore(1000000000); got != want {
+		t.Errorf("ScoreCorpus() = %.4f; want %.4f", got, want)
+	}
+}
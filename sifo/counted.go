@@ -0,0 +1,208 @@
+package sifo
+
+// MinSupport is the smallest summed word count (in words.csv's "Count Per Billion" units) a feature needs before it
+// counts as English. A prefix such as "ety" that only a couple of rare words start with is noise, not evidence that
+// an encoding reads like English. Zero keeps every feature that occurs at all.
+type MinSupport struct {
+	Prefixes        int64
+	Suffixes        int64
+	Middles         int64
+	WordPatterns    int64
+	VowelGroups     int64
+	ConsonantGroups int64
+	Boundaries      int64 // both vowel-consonant and consonant-vowel boundaries
+}
+
+// CountedDictionary is a Dictionary whose feature sets keep how often each feature occurs, weighted by the count of
+// each word it occurs in. Use Dictionary to turn it into the sets Score uses, dropping features below a minimum
+// support.
+type CountedDictionary struct {
+	Words                    map[string]int64
+	Prefixes                 map[string]int64
+	Suffixes                 map[string]int64
+	Middles                  map[string]int64
+	WordPatterns             map[string]int64
+	VowelGroups              map[string]int64
+	ConsonantGroups          map[string]int64
+	VowelConsonantBoundaries map[string]int64
+	ConsonantVowelBoundaries map[string]int64
+	Language                 Language
+}
+
+// NewCountedDictionary counts the features of words in the language.
+func NewCountedDictionary(words map[string]int64, lang Language) CountedDictionary {
+	lang = lang.orDefault()
+	prefixes, suffixes := lang.PrefixAndSuffixCounts(words)
+
+	return CountedDictionary{
+		Words:                    words,
+		Prefixes:                 prefixes,
+		Suffixes:                 suffixes,
+		Middles:                  lang.MiddleCounts(words),
+		WordPatterns:             lang.WordPatternCounts(words),
+		VowelGroups:              lang.VowelGroupCounts(words),
+		ConsonantGroups:          lang.ConsonantGroupCounts(words),
+		VowelConsonantBoundaries: lang.VowelConsonantBoundaryCounts(words),
+		ConsonantVowelBoundaries: lang.ConsonantVowelBoundaryCounts(words),
+		Language:                 lang,
+	}
+}
+
+// Dictionary returns the feature sets of the features with at least the minimum support. The anti sets still hold
+// only combinations that never occur, so a rare feature neither helps nor hurts an encoding.
+func (c CountedDictionary) Dictionary(support MinSupport) Dictionary {
+	lang := c.Language.orDefault()
+
+	return Dictionary{
+		Words:                    c.Words,
+		Prefixes:                 supported(c.Prefixes, support.Prefixes),
+		Suffixes:                 supported(c.Suffixes, support.Suffixes),
+		Middles:                  supported(c.Middles, support.Middles),
+		AntiPrefixes:             lang.AntiPrefixes(c.Words),
+		AntiSuffixes:             lang.AntiSuffixes(c.Words),
+		AntiMiddles:              lang.AntiMiddles(c.Words),
+		WordPatterns:             supported(c.WordPatterns, support.WordPatterns),
+		VowelGroups:              supported(c.VowelGroups, support.VowelGroups),
+		ConsonantGroups:          supported(c.ConsonantGroups, support.ConsonantGroups),
+		VowelConsonantBoundaries: supported(c.VowelConsonantBoundaries, support.Boundaries),
+		ConsonantVowelBoundaries: supported(c.ConsonantVowelBoundaries, support.Boundaries),
+		Language:                 lang,
+	}
+}
+
+// supported returns the set of features whose count is at least threshold.
+func supported(counts map[string]int64, threshold int64) map[string]bool {
+	features := make(map[string]bool)
+	for feature, count := range counts {
+		if count >= threshold {
+			features[feature] = true
+		}
+	}
+	return features
+}
+
+func PrefixAndSuffixCounts(words map[string]int64) (map[string]int64, map[string]int64) {
+	return English.PrefixAndSuffixCounts(words)
+}
+
+// PrefixAndSuffixCounts counts the 2 and 3 letter prefixes and suffixes of the words, weighted by each word's count.
+// As in PrefixesAndSuffixes, a word must be longer than a prefix or suffix to have it.
+func (l Language) PrefixAndSuffixCounts(words map[string]int64) (map[string]int64, map[string]int64) {
+	prefixes := make(map[string]int64)
+	suffixes := make(map[string]int64)
+
+	for word, count := range words {
+		sp := newSpelling(word)
+		length := sp.len()
+		if length >= 3 {
+			prefixes[sp.slice(0, 2)] += count
+			suffixes[sp.slice(length-2, length)] += count
+		}
+		if length >= 4 {
+			prefixes[sp.slice(0, 3)] += count
+			suffixes[sp.slice(length-3, length)] += count
+		}
+	}
+
+	return prefixes, suffixes
+}
+
+func MiddleCounts(words map[string]int64) map[string]int64 {
+	return English.MiddleCounts(words)
+}
+
+// MiddleCounts counts the 2, 3 and 4 letter middles of the words, weighted by each word's count. A middle never
+// includes a word's first or last letter.
+func (l Language) MiddleCounts(words map[string]int64) map[string]int64 {
+	middles := make(map[string]int64)
+
+	for word, count := range words {
+		sp := newSpelling(word)
+		length := sp.len()
+		if length >= 4 {
+			for i := 1; i < length-2; i++ {
+				middles[sp.slice(i, i+2)] += count
+			}
+		}
+		if length >= 5 {
+			for i := 1; i < length-3; i++ {
+				middles[sp.slice(i, i+3)] += count
+			}
+		}
+		if length >= 6 {
+			for i := 1; i < length-4; i++ {
+				middles[sp.slice(i, i+4)] += count
+			}
+		}
+	}
+
+	return middles
+}
+
+func WordPatternCounts(words map[string]int64) map[string]int64 {
+	return English.WordPatternCounts(words)
+}
+
+// WordPatternCounts counts the vowel/consonant pattern of each word (see wordPattern), weighted by the word's count.
+func (l Language) WordPatternCounts(words map[string]int64) map[string]int64 {
+	patterns := make(map[string]int64)
+
+	for word, count := range words {
+		patterns[l.wordPattern(word)] += count
+	}
+
+	return patterns
+}
+
+func VowelGroupCounts(words map[string]int64) map[string]int64 {
+	return English.VowelGroupCounts(words)
+}
+
+// VowelGroupCounts counts the vowel groups of the words, weighted by each word's count. A group that occurs twice in
+// a word counts once.
+func (l Language) VowelGroupCounts(words map[string]int64) map[string]int64 {
+	return countEach(words, l.vowelGroups)
+}
+
+func ConsonantGroupCounts(words map[string]int64) map[string]int64 {
+	return English.ConsonantGroupCounts(words)
+}
+
+// ConsonantGroupCounts counts the consonant groups of the words, weighted by each word's count. A group that occurs
+// twice in a word counts once.
+func (l Language) ConsonantGroupCounts(words map[string]int64) map[string]int64 {
+	return countEach(words, l.consonantGroups)
+}
+
+func VowelConsonantBoundaryCounts(words map[string]int64) map[string]int64 {
+	return English.VowelConsonantBoundaryCounts(words)
+}
+
+// VowelConsonantBoundaryCounts counts the vowel-to-consonant boundaries of the words, weighted by each word's count.
+// A boundary that occurs twice in a word counts once.
+func (l Language) VowelConsonantBoundaryCounts(words map[string]int64) map[string]int64 {
+	return countEach(words, l.vowelConsonantBoundaries)
+}
+
+func ConsonantVowelBoundaryCounts(words map[string]int64) map[string]int64 {
+	return English.ConsonantVowelBoundaryCounts(words)
+}
+
+// ConsonantVowelBoundaryCounts counts the consonant-to-vowel boundaries of the words, weighted by each word's count.
+// A boundary that occurs twice in a word counts once.
+func (l Language) ConsonantVowelBoundaryCounts(words map[string]int64) map[string]int64 {
+	return countEach(words, l.consonantVowelBoundaries)
+}
+
+// countEach adds each word's count to every feature features returns for it.
+func countEach(words map[string]int64, features func(word string) []string) map[string]int64 {
+	counts := make(map[string]int64)
+
+	for word, count := range words {
+		for _, feature := range features(word) {
+			counts[feature] += count
+		}
+	}
+
+	return counts
+}
@@ -0,0 +1,68 @@
+package sifo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCountedFeatures(t *testing.T) {
+	words := map[string]int64{"street": 10, "strong": 4, "beautiful": 2}
+
+	tests := []struct {
+		name     string
+		counts   map[string]int64
+		feature  string
+		expected int64
+	}{
+		{"prefix", first(PrefixAndSuffixCounts(words)), "str", 14},
+		{"prefix", first(PrefixAndSuffixCounts(words)), "be", 2},
+		{"suffix", second(PrefixAndSuffixCounts(words)), "ful", 2},
+		{"middle", MiddleCounts(words), "tr", 14},
+		{"middle", MiddleCounts(words), "tron", 4},
+		{"word pattern", WordPatternCounts(words), "cvc", 14},
+		{"vowel group", VowelGroupCounts(words), "ee", 10},
+		{"vowel group", VowelGroupCounts(words), "u", 2}, // twice in "beautiful", counted once
+		{"consonant group", ConsonantGroupCounts(words), "str", 14},
+		{"vowel-consonant boundary", VowelConsonantBoundaryCounts(words), "on", 4},
+		{"consonant-vowel boundary", ConsonantVowelBoundaryCounts(words), "re", 10},
+	}
+
+	for _, test := range tests {
+		if count := test.counts[test.feature]; count != test.expected {
+			t.Errorf("%s count of %q = %d; want %d", test.name, test.feature, count, test.expected)
+		}
+	}
+}
+
+func TestCountedBooleanAgreement(t *testing.T) {
+	words := map[string]int64{"street": 10, "strong": 4, "beautiful": 2, "a": 1}
+	counted := NewCountedDictionary(words, English).Dictionary(MinSupport{})
+
+	if !reflect.DeepEqual(counted, NewDictionary(words, English)) {
+		t.Errorf("CountedDictionary.Dictionary(MinSupport{}) differs from NewDictionary")
+	}
+}
+
+func TestCountedDictionaryMinSupport(t *testing.T) {
+	words := map[string]int64{"street": 1000, "strong": 400, "variety": 3, "society": 2}
+	dict := NewCountedDictionary(words, English).Dictionary(MinSupport{Suffixes: 10, Middles: 10})
+
+	if !dict.Suffixes["eet"] {
+		t.Errorf("Suffixes is missing the common suffix %q", "eet")
+	}
+	if dict.Suffixes["ety"] {
+		t.Errorf("Suffixes includes the rare suffix %q", "ety")
+	}
+	if dict.AntiSuffixes["ety"] {
+		t.Errorf("AntiSuffixes includes the rare but occurring suffix %q", "ety")
+	}
+	if dict.Middles["ci"] || !dict.Middles["tr"] {
+		t.Errorf("Middles = %v; want the rare middle %q dropped and %q kept", dict.Middles, "ci", "tr")
+	}
+	if !dict.Prefixes["va"] {
+		t.Errorf("Prefixes is missing %q with no minimum support", "va")
+	}
+}
+
+func first(a, _ map[string]int64) map[string]int64  { return a }
+func second(_, b map[string]int64) map[string]int64 { return b }
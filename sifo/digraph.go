@@ -0,0 +1,206 @@
+package sifo
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// CommonDigraphs are the two-letter keys a digraph cipher may encode as a unit. They are among the most frequent
+// letter pairs in English.
+var CommonDigraphs = []string{
+	"an", "ar", "as", "at", "ed", "en", "er", "ha", "he", "hi", "in",
+	"is", "it", "nd", "of", "on", "or", "ou", "qu", "re", "th", "to",
+}
+
+// UniquelyDecodable reports whether every concatenation of the codewords splits back into them in only one way. It
+// uses the Sardinas–Patterson algorithm: the code is ambiguous exactly when some dangling suffix, what is left of one
+// parse after another parse ends, is itself a codeword.
+func UniquelyDecodable(codewords []string) bool {
+	code := make(map[string]bool, len(codewords))
+	for _, word := range codewords {
+		if word == "" || code[word] {
+			return false
+		}
+		code[word] = true
+	}
+	_, ambiguous := danglingCodeword(code)
+	return !ambiguous
+}
+
+// danglingCodeword returns a codeword that is also a dangling suffix of the code, if there is one.
+func danglingCodeword(code map[string]bool) (string, bool) {
+	seen := make(map[string]bool)
+	suffixes := danglingSuffixes(code, code)
+	for len(suffixes) > 0 {
+		for suffix := range suffixes {
+			if code[suffix] {
+				return suffix, true
+			}
+			seen[suffix] = true
+		}
+
+		next := danglingSuffixes(suffixes, code)
+		for suffix := range danglingSuffixes(code, suffixes) {
+			next[suffix] = true
+		}
+		for suffix := range next {
+			if seen[suffix] {
+				delete(next, suffix)
+			}
+		}
+		suffixes = next
+	}
+	return "", false
+}
+
+// danglingSuffixes returns what is left of each word of b after a different word of a that starts it.
+func danglingSuffixes(a, b map[string]bool) map[string]bool {
+	suffixes := make(map[string]bool)
+	for x := range a {
+		for y := range b {
+			if len(x) < len(y) && strings.HasPrefix(y, x) {
+				suffixes[y[len(x):]] = true
+			}
+		}
+	}
+	return suffixes
+}
+
+// CheckDecodable returns an error wrapping ErrAmbiguousCipher if the cipher's encodings cannot be split back into
+// values in only one way. The letters of values that are not keys pass through encoding unchanged, so they count as
+// values too. A uniquely decodable cipher whose values are not also prefix-free, such as one with values "a", "ab"
+// and "bb", needs more lookahead than Decode's longest match; Inverse checks for that stricter property.
+func CheckDecodable(cipher Cipher) error {
+	owners := make(map[string]string, len(cipher))
+	keys := make([]string, 0, len(cipher))
+	for key := range cipher {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	passThrough := make(map[string]bool)
+	for _, key := range keys {
+		value := cipher[key]
+		if value == "" {
+			return fmt.Errorf("%w: %q encodes to %q", ErrAmbiguousCipher, key, value)
+		}
+		if other, ok := owners[value]; ok {
+			return fmt.Errorf("%w: %q and %q both encode to %q", ErrAmbiguousCipher, other, key, value)
+		}
+		owners[value] = key
+
+		for _, r := range value {
+			if _, ok := cipher[string(r)]; !ok {
+				passThrough[string(r)] = true
+			}
+		}
+	}
+	code := make(map[string]bool, len(owners)+len(passThrough))
+	for value := range owners {
+		code[value] = true
+	}
+	for letter := range passThrough {
+		code[letter] = true
+	}
+
+	if word, ambiguous := danglingCodeword(code); ambiguous {
+		return fmt.Errorf("%w: the values are not uniquely decodable; %q is left over by one parse and is itself a value", ErrAmbiguousCipher, word)
+	}
+	return nil
+}
+
+// generateRandomDigraphCipher generates a cipher over the language's letters and up to one fewer digraphs than there
+// are letters. One letter is set aside as an escape: every other letter is a one-letter value, and the escape
+// followed by a letter makes a two-letter value, as "q" does in "qu". The values are prefix-free and every letter in
+// them is a key, so the cipher always decodes. This is what generateRandomCipher could not do.
+func generateRandomDigraphCipher(r *rand.Rand, lang Language, digraphs []string) Cipher {
+	letters := lang.Letters()
+
+	pairs := make([]string, 0, len(digraphs))
+	for _, digraph := range digraphs {
+		if utf8.RuneCountInString(digraph) == 2 && !contains(pairs, digraph) {
+			pairs = append(pairs, digraph)
+		}
+	}
+	r.Shuffle(len(pairs), func(i, j int) { pairs[i], pairs[j] = pairs[j], pairs[i] })
+	if len(pairs) > len(letters)-1 {
+		pairs = pairs[:len(letters)-1]
+	}
+
+	escape := letters[r.Intn(len(letters))]
+	var values []string
+	for _, letter := range letters {
+		if letter != escape {
+			values = append(values, letter)
+		}
+	}
+	seconds := append([]string(nil), letters...)
+	r.Shuffle(len(seconds), func(i, j int) { seconds[i], seconds[j] = seconds[j], seconds[i] })
+	for _, second := range seconds[:len(pairs)+1] {
+		values = append(values, escape+second)
+	}
+	r.Shuffle(len(values), func(i, j int) { values[i], values[j] = values[j], values[i] })
+
+	// Swapping a value that matches its key with the next one leaves neither matching.
+	keys := append(letters, pairs...)
+	for i, key := range keys {
+		if values[i] == key {
+			j := (i + 1) % len(values)
+			values[i], values[j] = values[j], values[i]
+		}
+	}
+
+	cipher := make(Cipher, len(values))
+	for i, key := range keys {
+		cipher[key] = values[i]
+	}
+	return cipher
+}
+
+// swapDigraph returns a copy of the cipher with one of its digraph keys replaced by one of the digraphs it does not
+// have, which keeps that key's value. The values are unchanged, so a cipher that decodes still does. A cipher without
+// digraph keys, or already using every digraph other than the value, is returned as it is.
+func swapDigraph(cipher Cipher, r *rand.Rand, digraphs []string) Cipher {
+	var used []string
+	for key := range cipher {
+		if utf8.RuneCountInString(key) > 1 {
+			used = append(used, key)
+		}
+	}
+	if len(used) == 0 {
+		return cipher
+	}
+	sort.Strings(used)
+	old := used[r.Intn(len(used))]
+	value := cipher[old]
+
+	var unused []string
+	for _, digraph := range digraphs {
+		if _, ok := cipher[digraph]; !ok && digraph != value && utf8.RuneCountInString(digraph) == 2 {
+			unused = append(unused, digraph)
+		}
+	}
+	if len(unused) == 0 {
+		return cipher
+	}
+
+	newCipher := make(Cipher, len(cipher))
+	for k, v := range cipher {
+		newCipher[k] = v
+	}
+	delete(newCipher, old)
+	newCipher[unused[r.Intn(len(unused))]] = value
+	return newCipher
+}
+
+func contains(ss []string, s string) bool {
+	for _, t := range ss {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
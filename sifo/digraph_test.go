@@ -0,0 +1,133 @@
+package sifo
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUniquelyDecodable(t *testing.T) {
+	tests := []struct {
+		codewords []string
+		expected  bool
+	}{
+		{[]string{"a", "b", "c"}, true},
+		{[]string{"b", "ca", "cb", "qu"}, true},
+		{[]string{"a", "ab", "bb"}, true}, // not prefix-free, but still decodable
+		{[]string{"a", "ab", "b"}, false},
+		{[]string{"0", "01", "10"}, false},
+		{[]string{"1", "011", "01110", "1110", "10011"}, false},
+		{[]string{"a", "a"}, false},
+		{[]string{"a", ""}, false},
+	}
+
+	for _, test := range tests {
+		if result := UniquelyDecodable(test.codewords); result != test.expected {
+			t.Errorf("UniquelyDecodable(%q) = %t; want %t", test.codewords, result, test.expected)
+		}
+	}
+}
+
+func TestCheckDecodable(t *testing.T) {
+	tests := []struct {
+		name   string
+		cipher Cipher
+		want   string // "" if the cipher decodes
+	}{
+		{"single letters", WarmHoldCipher(), ""},
+		{"prefix-free", Cipher{"a": "b", "b": "ca", "c": "cb", "th": "a", "t": "cc", "h": "cd", "d": "ce", "e": "d"}, ""},
+		{"not prefix-free", Cipher{"a": "a", "b": "ab", "c": "bb"}, ""},
+		{"pass-through", Cipher{"a": "x", "b": "xy"}, "not uniquely decodable"},
+		{"ambiguous", Cipher{"a": "a", "b": "ab", "c": "b"}, "not uniquely decodable"},
+		{"duplicate value", Cipher{"a": "b", "b": "b"}, `"a" and "b" both encode to "b"`},
+	}
+
+	for _, test := range tests {
+		err := CheckDecodable(test.cipher)
+		if test.want == "" {
+			if err != nil {
+				t.Errorf("CheckDecodable() of %s error = %v", test.name, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrAmbiguousCipher) || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("CheckDecodable() of %s error = %v; want ErrAmbiguousCipher containing %q", test.name, err, test.want)
+		}
+	}
+}
+
+// checkDigraphCipher checks that a digraph cipher is valid and decodes, both by CheckDecodable and by Decode.
+func checkDigraphCipher(t *testing.T, cipher Cipher) {
+	t.Helper()
+	if err := CheckDecodable(cipher); err != nil {
+		t.Fatalf("CheckDecodable(%v) error = %v", cipher, err)
+	}
+	if err := Validate(cipher, English.Alphabet); err != nil {
+		t.Fatalf("Validate(%v) error = %v", cipher, err)
+	}
+	if _, err := Inverse(cipher); err != nil {
+		t.Fatalf("Inverse(%v) error = %v", cipher, err)
+	}
+	decoded, err := Decode(EncodeText(quote, cipher), cipher)
+	if err != nil || decoded != quote {
+		t.Fatalf("Decode(EncodeText(quote)) with %v = %q, %v; want the quote", cipher, decoded, err)
+	}
+}
+
+func TestGenerateRandomDigraphCipher(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		cipher := generateRandomDigraphCipher(r, English, CommonDigraphs)
+
+		if want := len(English.Alphabet) + len(CommonDigraphs); len(cipher) != want {
+			t.Fatalf("generateRandomDigraphCipher() has %d keys; want %d", len(cipher), want)
+		}
+		for _, digraph := range CommonDigraphs {
+			if _, ok := cipher[digraph]; !ok {
+				t.Fatalf("generateRandomDigraphCipher() is missing digraph %q", digraph)
+			}
+		}
+		checkDigraphCipher(t, cipher)
+
+		// Mutations keep the cipher decodable.
+		for i := 0; i < 20; i++ {
+			cipher = varyCipher(cipher, r, 1+r.Intn(2))
+			cipher = swapDigraph(cipher, r, append(CommonDigraphs, "ch", "sh"))
+			checkDigraphCipher(t, cipher)
+		}
+	}
+}
+
+func TestGenerateRandomDigraphCipherLimit(t *testing.T) {
+	// There are only as many two-letter values as letters to follow the escape, one of them for its own letter.
+	var digraphs []string
+	for _, a := range "abcdef" {
+		for _, b := range "abcdef" {
+			digraphs = append(digraphs, string(a)+string(b))
+		}
+	}
+
+	cipher := generateRandomDigraphCipher(rand.New(rand.NewSource(1)), English, digraphs)
+	if want := 2*len(English.Alphabet) - 1; len(cipher) != want {
+		t.Errorf("generateRandomDigraphCipher() has %d keys; want %d", len(cipher), want)
+	}
+	checkDigraphCipher(t, cipher)
+}
+
+func TestSwapDigraph(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	cipher := Cipher{"a": "b", "b": "ca", "c": "cb", "th": "a", "t": "cc", "h": "cd", "d": "ce", "e": "d"}
+
+	swapped := swapDigraph(cipher, r, []string{"th", "qu"})
+	if _, ok := swapped["th"]; ok || swapped["qu"] != "a" {
+		t.Errorf("swapDigraph() = %v; want th replaced by qu", swapped)
+	}
+	if cipher["th"] != "a" {
+		t.Errorf("swapDigraph() modified its argument")
+	}
+
+	if single := WarmHoldCipher(); !equal(swapDigraph(single, r, CommonDigraphs), single) {
+		t.Errorf("swapDigraph() changed a cipher without digraphs")
+	}
+}
@@ -0,0 +1,251 @@
+package sifo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Format is the file format of a word list.
+type Format int
+
+const (
+	FormatAuto      Format = iota // sniffed from the content
+	FormatCSV                     // "word,count" lines, like words.csv
+	FormatTSV                     // "word<tab>count" lines
+	FormatText                    // one word per line, most frequent first, like words.txt
+	FormatJSON                    // an object of words to counts, or an array of records or [word, count] pairs
+	FormatJSONLines               // one {"word": ..., "count": ...} record per line
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatAuto:
+		return "auto"
+	case FormatCSV:
+		return "csv"
+	case FormatTSV:
+		return "tsv"
+	case FormatText:
+		return "text"
+	case FormatJSON:
+		return "json"
+	case FormatJSONLines:
+		return "jsonl"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// FormatFromFilename returns the format for a file's extension, ignoring a trailing ".gz". Unknown extensions are
+// FormatAuto.
+func FormatFromFilename(filename string) Format {
+	filename = strings.TrimSuffix(strings.ToLower(filename), ".gz")
+	switch filepath.Ext(filename) {
+	case ".csv":
+		return FormatCSV
+	case ".tsv", ".tab":
+		return FormatTSV
+	case ".txt":
+		return FormatText
+	case ".json":
+		return FormatJSON
+	case ".jsonl", ".ndjson":
+		return FormatJSONLines
+	}
+	return FormatAuto
+}
+
+// sniffFormat guesses the format of a word list from its first few kilobytes. JSON starts with "[" or "{", and is
+// JSON Lines when its first line is a complete object. Otherwise lines with tabs are TSV, lines with commas are CSV,
+// and anything else is a ranked plain-text list.
+func sniffFormat(br *bufio.Reader) Format {
+	head, _ := br.Peek(4096)
+	trimmed := bytes.TrimLeft(head, " \t\r\n\ufeff")
+
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		firstLine, _, _ := bytes.Cut(trimmed, []byte("\n"))
+		if trimmed[0] == '{' && json.Valid(bytes.TrimSpace(firstLine)) && bytes.Contains(firstLine, []byte(`"word"`)) {
+			return FormatJSONLines
+		}
+		return FormatJSON
+	}
+
+	// Only look at complete lines, the last one may be cut off
+	if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 && len(head) == 4096 {
+		trimmed = trimmed[:i]
+	}
+	switch {
+	case bytes.ContainsRune(trimmed, '\t'):
+		return FormatTSV
+	case bytes.ContainsRune(trimmed, ','):
+		return FormatCSV
+	}
+	return FormatText
+}
+
+// zipfCounts estimates the counts per billion of a list of n words ranked by frequency. By Zipf's law, frequency is
+// inversely proportional to rank, so the word at rank r (starting at 1) makes up 1 / (r * H(n)) of all words, where
+// H(n) is the n-th harmonic number. The count for rank r is at index r-1.
+func zipfCounts(n int) []int64 {
+	var harmonic float64
+	for k := 1; k <= n; k++ {
+		harmonic += 1 / float64(k)
+	}
+
+	counts := make([]int64, n)
+	for i := range counts {
+		counts[i] = int64(1e9 / (float64(i+1) * harmonic))
+	}
+	return counts
+}
+
+// readRanked reads a plain list of words, one per line and most frequent first, and estimates counts from each
+// word's rank with zipfCounts. Blank lines and lines starting with "#" are ignored. Only the first field of a line
+// is used.
+func readRanked(r io.Reader, list *wordList) error {
+	type entry struct {
+		line int
+		word string
+	}
+
+	var entries []entry
+	firstLines := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		word := strings.Fields(text)[0]
+		if firstLine, ok := firstLines[word]; ok {
+			list.skip(line, text, fmt.Sprintf("duplicate of line %d", firstLine))
+			continue
+		}
+		firstLines[word] = line
+		entries = append(entries, entry{line, word})
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	counts := zipfCounts(len(entries))
+	for i, e := range entries {
+		list.add(e.line, e.word, e.word, counts[i])
+	}
+
+	return nil
+}
+
+// readJSON reads a JSON word list: an object of words to counts ({"the": 56271872}), an array of records
+// ([{"word": "the", "count": 56271872}]) or an array of pairs ([["the", 56271872]]). Line numbers in skipped entries
+// are array indexes, starting at 1.
+func readJSON(r io.Reader, list *wordList) error {
+	var doc any
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return fmt.Errorf("reading JSON word list: %w", err)
+	}
+
+	switch doc := doc.(type) {
+	case map[string]any:
+		for word, value := range doc {
+			count, err := jsonCount(value)
+			if err != nil {
+				list.skip(0, word, err.Error())
+				continue
+			}
+			list.add(0, word, word, count)
+		}
+	case []any:
+		for i, item := range doc {
+			text, _ := json.Marshal(item)
+			word, count, err := jsonEntry(item)
+			if err != nil {
+				list.skip(i+1, string(text), err.Error())
+				continue
+			}
+			list.add(i+1, string(text), word, count)
+		}
+	default:
+		return errors.New("reading JSON word list: expected an object or an array")
+	}
+
+	return nil
+}
+
+// readJSONLines reads one JSON record or [word, count] pair per line. Blank lines are ignored.
+func readJSONLines(r io.Reader, list *wordList) error {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var item any
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.UseNumber()
+		if err := decoder.Decode(&item); err != nil {
+			list.skip(line, text, "invalid JSON")
+			continue
+		}
+		word, count, err := jsonEntry(item)
+		if err != nil {
+			list.skip(line, text, err.Error())
+			continue
+		}
+		list.add(line, text, word, count)
+	}
+
+	return scanner.Err()
+}
+
+// jsonEntry returns the word and count of a {"word": ..., "count": ...} record or a [word, count] pair.
+func jsonEntry(item any) (string, int64, error) {
+	switch item := item.(type) {
+	case map[string]any:
+		word, ok := item["word"].(string)
+		if !ok {
+			return "", 0, errors.New("missing word")
+		}
+		value, ok := item["count"]
+		if !ok {
+			return "", 0, errors.New("missing count")
+		}
+		count, err := jsonCount(value)
+		return word, count, err
+	case []any:
+		if len(item) < 2 {
+			return "", 0, errors.New("missing count")
+		}
+		word, ok := item[0].(string)
+		if !ok {
+			return "", 0, errors.New("missing word")
+		}
+		count, err := jsonCount(item[1])
+		return word, count, err
+	}
+	return "", 0, errors.New("expected a record or a pair")
+}
+
+func jsonCount(value any) (int64, error) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, errors.New("count is not an integer")
+	}
+	count, err := number.Int64()
+	if err != nil {
+		return 0, errors.New("count is not an integer")
+	}
+	return count, nil
+}
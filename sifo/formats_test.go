@@ -0,0 +1,159 @@
+package sifo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatFromFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		expected Format
+	}{
+		{"words.csv", FormatCSV},
+		{"words.txt", FormatText},
+		{"words.TSV", FormatTSV},
+		{"words.json", FormatJSON},
+		{"words.jsonl", FormatJSONLines},
+		{"words.ndjson", FormatJSONLines},
+		{"words.csv.gz", FormatCSV},
+		{"words.gz", FormatAuto},
+		{"words", FormatAuto},
+	}
+
+	for _, test := range tests {
+		result := FormatFromFilename(test.filename)
+		if result != test.expected {
+			t.Errorf("FormatFromFilename(%q) = %v; want %v", test.filename, result, test.expected)
+		}
+	}
+}
+
+func TestSniffFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Format
+	}{
+		{"Word,Count Per Billion\nthe,56271872\n", FormatCSV},
+		{"the\t56271872\nof\t33950064\n", FormatTSV},
+		{"the\nof\nand\n", FormatText},
+		{`{"the": 56271872, "of": 33950064}`, FormatJSON},
+		{"[[\"the\", 56271872]]", FormatJSON},
+		{"{\"word\": \"the\", \"count\": 56271872}\n{\"word\": \"of\", \"count\": 33950064}\n", FormatJSONLines},
+		{"\ufeff  the\nof\n", FormatText},
+		{"", FormatText},
+	}
+
+	for _, test := range tests {
+		result := sniffFormat(bufio.NewReader(strings.NewReader(test.input)))
+		if result != test.expected {
+			t.Errorf("sniffFormat(%q) = %v; want %v", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestReadWordsFormats(t *testing.T) {
+	expected := map[string]int64{
+		"the": 56271872,
+		"of":  33950064,
+	}
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"csv", "Word,Count Per Billion\nthe,56271872\nof,33950064\n"},
+		{"tsv", "Word\tCount\nthe\t56271872\nof\t33950064\n"},
+		{"json object", `{"the": 56271872, "of": 33950064}`},
+		{"json records", `[{"word": "the", "count": 56271872}, {"word": "of", "count": 33950064}]`},
+		{"json pairs", `[["the", 56271872], ["of", 33950064]]`},
+		{"json lines", "{\"word\": \"the\", \"count\": 56271872}\n\n[\"of\", 33950064]\n"},
+	}
+
+	for _, test := range tests {
+		for _, gzipped := range []bool{false, true} {
+			input := []byte(test.input)
+			if gzipped {
+				var buf bytes.Buffer
+				gz := gzip.NewWriter(&buf)
+				gz.Write(input)
+				gz.Close()
+				input = buf.Bytes()
+			}
+
+			words, err := ReadWords(bytes.NewReader(input), ReadOptions{})
+			if err != nil {
+				t.Fatalf("ReadWords(%s, gzipped %v) error = %v", test.name, gzipped, err)
+			}
+			if !reflect.DeepEqual(words, expected) {
+				t.Errorf("ReadWords(%s, gzipped %v) = %v; want %v", test.name, gzipped, words, expected)
+			}
+		}
+	}
+}
+
+func TestReadWordsJSONLinesSkipped(t *testing.T) {
+	input := `{"word": "the", "count": 56271872}
+not json
+{"word": "of"}
+{"count": 5}
+["and", "lots"]
+{"word": "the", "count": 1}
+`
+
+	var report ReadReport
+	words, err := ReadWords(strings.NewReader(input), ReadOptions{Format: FormatJSONLines, Report: &report})
+	if err != nil {
+		t.Fatalf("ReadWords() error = %v", err)
+	}
+	if len(words) != 1 {
+		t.Errorf("ReadWords() loaded %d words; want 1", len(words))
+	}
+
+	var reasons []string
+	for _, s := range report.Skipped {
+		reasons = append(reasons, s.Reason)
+	}
+	expected := []string{"invalid JSON", "missing count", "missing word", "count is not an integer", "duplicate of line 1"}
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("ReadWords() skipped reasons = %v; want %v", reasons, expected)
+	}
+}
+
+func TestReadWordsRanked(t *testing.T) {
+	var report ReadReport
+	words, err := ReadWords(strings.NewReader("# ranked\nthe\nof\n\nand\nof\n"), ReadOptions{Report: &report})
+	if err != nil {
+		t.Fatalf("ReadWords() error = %v", err)
+	}
+	if report.Format != FormatText {
+		t.Errorf("ReadWords() format = %v; want %v", report.Format, FormatText)
+	}
+
+	// H(3) = 11/6, so the top word makes up 6/11 of all words, the next half that, and so on
+	expected := map[string]int64{
+		"the": 545454545,
+		"of":  272727272,
+		"and": 181818181,
+	}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("ReadWords() = %v; want %v", words, expected)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].Reason != "duplicate of line 3" {
+		t.Errorf("ReadWords() skipped = %v; want the repeated %q", report.Skipped, "of")
+	}
+}
+
+func TestLoadWordsText(t *testing.T) {
+	words := LoadWords("../words.txt")
+	if len(words) < 9900 {
+		t.Errorf("LoadWords(words.txt) loaded %d words; want the whole list", len(words))
+	}
+	if words["the"] <= words["of"] || words["of"] <= words["and"] {
+		t.Errorf("LoadWords(words.txt) counts for the, of, and = %d, %d, %d; want decreasing", words["the"], words["of"], words["and"])
+	}
+}
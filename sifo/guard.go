@@ -0,0 +1,142 @@
+package sifo
+
+import (
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// guardWeight is the share of a word's occurrence score taken away when its encoding is a blocked word, so that
+	// a cipher turning a common word into an offensive one costs more than one doing it to a rare word.
+	guardWeight = 20.0
+
+	// minEmbeddedLength is the shortest blocked word looked for inside encodings. Shorter ones are found inside too
+	// many innocent words to be worth reporting.
+	minEmbeddedLength = 4
+)
+
+// Guard keeps ciphers from turning everyday words into offensive or sensitive ones. Encoded output is shown to end
+// users, so the guard reports every word whose encoding is blocked and either penalizes such ciphers in Score or
+// forbids them outright.
+type Guard struct {
+	Blocklist map[string]bool // lowercase blocked words
+	Embedded  bool            // also block encodings that contain a blocked word of minEmbeddedLength or more letters
+	Forbid    bool            // score ciphers with any hit as -Inf so the search never chooses them
+}
+
+// GuardHit is a word whose encoding the guard blocks.
+type GuardHit struct {
+	Word    string
+	Encoded string
+	Blocked string // the blocked word the encoding is or contains
+}
+
+// LoadBlocklist reads a blocklist of words, one per line. Blank lines and lines starting with "#" are ignored.
+func LoadBlocklist(filename string) (map[string]bool, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	words, err := ReadWordSet(file)
+	if err != nil {
+		return nil, err
+	}
+
+	blocklist := make(map[string]bool, len(words))
+	for word := range words {
+		blocklist[strings.ToLower(word)] = true
+	}
+	return blocklist, nil
+}
+
+// Tagged returns the words the overlay tags with tag, such as the TagOffensive words to add to a blocklist.
+func (o Overlay) Tagged(tag Tag) []string {
+	var words []string
+	for word := range o {
+		if o.HasTag(word, tag) {
+			words = append(words, word)
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// blocked returns the blocked word that encoded is or contains, or "" if it is clean.
+func (g *Guard) blocked(encoded string) string {
+	encoded = strings.ToLower(encoded)
+	if g.Blocklist[encoded] {
+		return encoded
+	}
+	if !g.Embedded {
+		return ""
+	}
+
+	sp := newSpelling(encoded)
+	for i := 0; i < sp.len(); i++ {
+		for j := i + minEmbeddedLength; j <= sp.len(); j++ {
+			if sub := sp.slice(i, j); g.Blocklist[sub] {
+				return sub
+			}
+		}
+	}
+	return ""
+}
+
+// Hits returns the words of dict whose encodings the guard blocks, most common first.
+func (g *Guard) Hits(dict Dictionary, cipher Cipher) []GuardHit {
+	lang := dict.Language.orDefault()
+
+	var hits []GuardHit
+	for word := range dict.Words {
+		encoded := lang.encodeWord(word, cipher)
+		if blocked := g.blocked(encoded); blocked != "" {
+			hits = append(hits, GuardHit{Word: word, Encoded: encoded, Blocked: blocked})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if ci, cj := dict.Words[hits[i].Word], dict.Words[hits[j].Word]; ci != cj {
+			return ci > cj
+		}
+		return hits[i].Word < hits[j].Word
+	})
+	return hits
+}
+
+// CheckText returns the words of text whose encodings the guard blocks, in the order they appear, as Encode would
+// encode them. Punctuation around a word, as in "Here's" or "ones.", is not part of it.
+func (g *Guard) CheckText(text string, cipher Cipher) []GuardHit {
+	var hits []GuardHit
+	for _, field := range strings.Fields(text) {
+		word := strings.TrimFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r)
+		})
+		encoded := encodeWord(word, cipher)
+		if blocked := g.blocked(encoded); blocked != "" {
+			hits = append(hits, GuardHit{Word: word, Encoded: encoded, Blocked: blocked})
+		}
+	}
+	return hits
+}
+
+// Allows reports whether the guard lets the cipher encode dict's words with no hits.
+func (g *Guard) Allows(dict Dictionary, cipher Cipher) bool {
+	return len(g.Hits(dict, cipher)) == 0
+}
+
+// improves reports whether score beats best in the search. A forbidden cipher, scored -Inf, never does, and
+// anything else beats a forbidden one.
+func improves(score, best float64) bool {
+	if math.IsInf(score, -1) {
+		return false
+	}
+	if math.IsInf(best, -1) {
+		return true
+	}
+	return int64(score) > int64(best)
+}
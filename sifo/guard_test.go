@@ -0,0 +1,177 @@
+package sifo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestGuardBlocked(t *testing.T) {
+	guard := &Guard{Blocklist: map[string]bool{"heck": true, "darn": true, "dang": true}}
+
+	tests := []struct {
+		encoded  string
+		embedded bool
+		expected string
+	}{
+		{"heck", false, "heck"},
+		{"Heck", false, "heck"},
+		{"hecks", false, ""},
+		{"hecks", true, "heck"},
+		{"odarnit", true, "darn"},
+		{"dan", true, ""},
+		{"street", true, ""},
+	}
+
+	for _, test := range tests {
+		guard.Embedded = test.embedded
+		if got := guard.blocked(test.encoded); got != test.expected {
+			t.Errorf("blocked(%q) with Embedded %v = %q; want %q", test.encoded, test.embedded, got, test.expected)
+		}
+	}
+}
+
+func TestGuardHits(t *testing.T) {
+	words := map[string]int64{"hack": 100, "back": 1000, "deck": 10}
+	guard := &Guard{Blocklist: map[string]bool{"heck": true}}
+	cipher := Cipher{"a": "e", "e": "a", "b": "h", "h": "b", "d": "h"}
+
+	// hack -> beck and deck -> hack are clean
+	want := []GuardHit{{Word: "back", Encoded: "heck", Blocked: "heck"}}
+
+	hits := guard.Hits(Dictionary{Words: words}, cipher)
+	if !reflect.DeepEqual(hits, want) {
+		t.Errorf("Hits() = %v; want %v", hits, want)
+	}
+	if guard.Allows(Dictionary{Words: words}, cipher) {
+		t.Errorf("Allows() = true for a cipher with hits")
+	}
+	if !guard.Allows(Dictionary{Words: words}, Cipher{}) {
+		t.Errorf("Allows() = false for the identity cipher")
+	}
+}
+
+func TestGuardCheckText(t *testing.T) {
+	guard := &Guard{Blocklist: map[string]bool{"heck": true}}
+	cipher := Cipher{"a": "e", "e": "a", "b": "h", "h": "b"}
+
+	hits := guard.CheckText("Get back, now!", cipher)
+	want := []GuardHit{{Word: "back", Encoded: "heck", Blocked: "heck"}}
+	if !reflect.DeepEqual(hits, want) {
+		t.Errorf("CheckText() = %v; want %v", hits, want)
+	}
+}
+
+func TestScoreGuard(t *testing.T) {
+	words := map[string]int64{"dog": 1000000, "stop": 1000000}
+	cipher := completeCipher(t, Cipher{"d": "c", "c": "d", "o": "a", "a": "o", "g": "t", "t": "g"}) // dog -> cat
+	dict := NewDictionary(words, English)
+	plain := Score(dict, cipher, false)
+
+	dict.Guard = &Guard{Blocklist: map[string]bool{"cat": true}}
+	penalized := Score(dict, cipher, false)
+	if want := plain - guardWeight*occurrenceScore(1000000); math.Abs(penalized-want) > 1e-9 {
+		t.Errorf("Score() with a blocked encoding = %.4f; want %.4f", penalized, want)
+	}
+
+	dict.Guard.Forbid = true
+	if got := Score(dict, cipher, false); !math.IsInf(got, -1) {
+		t.Errorf("Score() of a forbidden cipher = %.4f; want -Inf", got)
+	}
+	if got := Score(dict, WarmHoldCipher(), false); math.IsInf(got, -1) {
+		t.Errorf("Score() of an allowed cipher = -Inf")
+	}
+}
+
+func TestImproves(t *testing.T) {
+	tests := []struct {
+		score, best float64
+		expected    bool
+	}{
+		{10, 5, true},
+		{5.5, 5.2, false}, // the search compares whole points
+		{5, 10, false},
+		{math.Inf(-1), 5, false},
+		{5, math.Inf(-1), true},
+		{-5, math.Inf(-1), true},
+		{math.Inf(-1), math.Inf(-1), false},
+	}
+
+	for _, test := range tests {
+		if got := improves(test.score, test.best); got != test.expected {
+			t.Errorf("improves(%v, %v) = %v; want %v", test.score, test.best, got, test.expected)
+		}
+	}
+}
+
+func TestShippedBlocklistGiants(t *testing.T) {
+	// Guard the giants the way main.go does: the blocklist and the overlay's offensive words, embedded matches too.
+	blocklist, err := LoadBlocklist("../blocklist.txt")
+	if err != nil {
+		t.Fatalf("LoadBlocklist() error = %v", err)
+	}
+	overlay, err := LoadOverlay("../words.overlay.csv")
+	if err != nil {
+		t.Fatalf("LoadOverlay() error = %v", err)
+	}
+	for _, word := range overlay.Tagged(TagOffensive) {
+		blocklist[word] = true
+	}
+	dict := Dictionary{Words: LoadWords("../words.csv")}
+	guard := &Guard{Blocklist: blocklist, Embedded: true}
+
+	tests := []struct {
+		name    string
+		cipher  Cipher
+		allowed bool
+	}{
+		{"LonelyRemark", LonelyRemarkCipher(), false}, // occupy -> eddick
+		{"MoonPeer", MoonPeerCipher(), false},         // evils -> acunt
+		{"WormHeld", WormHeldCipher(), false},         // baby -> cock, bids -> cunt
+		{"WarmHold", WarmHoldCipher(), true},          // no blocked words
+		{"WormHelp", WormHelpCipher(), false},         // evils -> acunt
+	}
+
+	for _, test := range tests {
+		hits := guard.Hits(dict, test.cipher)
+		switch {
+		case test.allowed && len(hits) > 0:
+			t.Errorf("%s produces %d blocked words, such as %s -> %s", test.name, len(hits), hits[0].Word, hits[0].Encoded)
+		case !test.allowed && len(hits) == 0:
+			t.Errorf("%s produces no blocked words; the blocklist is missing entries", test.name)
+		}
+	}
+}
+
+func TestFindBestCipherGuardForbidsGiants(t *testing.T) {
+	words := map[string]int64{"warm": 1000000, "lots": 800000, "tide": 500000, "moon": 300000}
+	dict := NewDictionary(words, English)
+
+	// Block what each giant encodes "warm" to, so that the guard forbids every giant.
+	blocklist := make(map[string]bool)
+	for _, cipher := range giantCiphers() {
+		blocklist[encodeWord("warm", cipher)] = true
+	}
+	dict.Guard = &Guard{Blocklist: blocklist, Forbid: true}
+	if gts := giants(dict); len(gts) != 0 {
+		t.Fatalf("giants() = %d giants; want the guard to forbid them all", len(gts))
+	}
+
+	cipher, err := FindBestCipher(dict, 100)
+	if err != nil {
+		t.Fatalf("FindBestCipher() error = %v", err)
+	}
+	if math.IsInf(Score(dict, cipher, false), -1) {
+		t.Errorf("FindBestCipher() = %v, which the guard forbids", cipher)
+	}
+
+	// When the guard forbids every cipher, there is nothing to search from.
+	dict = NewDictionary(map[string]int64{"a": 1000000}, English)
+	dict.Guard = &Guard{Blocklist: make(map[string]bool), Forbid: true}
+	for _, letter := range English.Letters() {
+		dict.Guard.Blocklist[letter] = true
+	}
+	if _, err := FindBestCipher(dict, 100); err == nil {
+		t.Errorf("FindBestCipher() with every cipher forbidden did not fail")
+	}
+}
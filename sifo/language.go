@@ -0,0 +1,127 @@
+package sifo
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Language describes the letters of the language an experiment is run in: the alphabet ciphers are built over, the
+// vowels that word patterns are made of, and any case rules beyond Unicode's defaults.
+type Language struct {
+	Name       string
+	Alphabet   []rune              // lowercase letters, in order
+	Vowels     []rune              // lowercase vowels, a subset of Alphabet
+	Semivowels []rune              // vowels that are often consonants, such as y, which alone do not voice a word
+	Case       unicode.SpecialCase // optional; nil uses Unicode's default case mappings
+}
+
+var (
+	English = Language{
+		Name:       "English",
+		Alphabet:   []rune("abcdefghijklmnopqrstuvwxyz"),
+		Vowels:     []rune("aeiouy"),
+		Semivowels: []rune("y"),
+	}
+
+	Spanish = Language{
+		Name:     "Spanish",
+		Alphabet: []rune("abcdefghijklmnñopqrstuvwxyzáéíóúü"),
+		Vowels:   []rune("aeiouyáéíóúü"),
+	}
+
+	// German has no single uppercase letter for ß in Unicode's default mappings, so it keeps its case.
+	German = Language{
+		Name:     "German",
+		Alphabet: []rune("abcdefghijklmnopqrstuvwxyzäöüß"),
+		Vowels:   []rune("aeiouyäöü"),
+	}
+)
+
+// orDefault returns the language, or English if it is the zero Language.
+func (l Language) orDefault() Language {
+	if len(l.Alphabet) == 0 {
+		return English
+	}
+	return l
+}
+
+// IsVowel reports whether the lowercase letter r is one of the language's vowels.
+func (l Language) IsVowel(r rune) bool {
+	for _, v := range l.Vowels {
+		if v == r {
+			return true
+		}
+	}
+	return false
+}
+
+// Letters returns the alphabet as one-letter strings, the form cipher keys take.
+func (l Language) Letters() []string {
+	letters := make([]string, len(l.Alphabet))
+	for i, r := range l.Alphabet {
+		letters[i] = string(r)
+	}
+	return letters
+}
+
+// ToLower lowercases s using the language's case rules.
+func (l Language) ToLower(s string) string {
+	if l.Case != nil {
+		return strings.ToLowerSpecial(l.Case, s)
+	}
+	return strings.ToLower(s)
+}
+
+// ToUpperRune uppercases r using the language's case rules.
+func (l Language) ToUpperRune(r rune) rune {
+	if l.Case != nil {
+		return l.Case.ToUpper(r)
+	}
+	return unicode.ToUpper(r)
+}
+
+// spelling indexes a word by letter rather than by byte, so that slicing a word never splits a multi-byte letter
+// such as "ñ". Words that are all ASCII are sliced by byte directly.
+type spelling struct {
+	word    string
+	offsets []int // byte offset of each letter, plus len(word); nil for ASCII words
+}
+
+func newSpelling(word string) spelling {
+	for i := 0; i < len(word); i++ {
+		if word[i] >= utf8.RuneSelf {
+			offsets := make([]int, 0, len(word)+1)
+			for j := range word {
+				offsets = append(offsets, j)
+			}
+			return spelling{word: word, offsets: append(offsets, len(word))}
+		}
+	}
+	return spelling{word: word}
+}
+
+// len returns the number of letters in the word.
+func (sp spelling) len() int {
+	if sp.offsets == nil {
+		return len(sp.word)
+	}
+	return len(sp.offsets) - 1
+}
+
+// slice returns letters i through j-1 of the word.
+func (sp spelling) slice(i, j int) string {
+	if sp.offsets == nil {
+		return sp.word[i:j]
+	}
+	return sp.word[sp.offsets[i]:sp.offsets[j]]
+}
+
+// at returns letter i of the word.
+func (sp spelling) at(i int) rune {
+	if sp.offsets == nil {
+		return rune(sp.word[i])
+	}
+	r, _ := utf8.DecodeRuneInString(sp.word[sp.offsets[i]:])
+	return r
+}
@@ -0,0 +1,156 @@
+package sifo
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"unicode"
+)
+
+func TestLanguageWordPattern(t *testing.T) {
+	tests := []struct {
+		lang     Language
+		word     string
+		expected string
+	}{
+		{English, "street", "cvc"},
+		{Spanish, "niño", "cvcv"},
+		{Spanish, "canción", "cvcvc"},
+		{German, "müller", "cvcvc"},
+		{German, "straße", "cvcv"},
+		{English, "müller", "cvc"}, // ü is not an English vowel, so "müll" is one consonant group
+	}
+
+	for _, test := range tests {
+		result := test.lang.wordPattern(test.word)
+		if result != test.expected {
+			t.Errorf("%s.wordPattern(%q) = %v; want %v", test.lang.Name, test.word, result, test.expected)
+		}
+	}
+}
+
+func TestLanguageBoundaries(t *testing.T) {
+	tests := []struct {
+		lang Language
+		word string
+		vc   []string
+		cv   []string
+	}{
+		{English, "banana", []string{"an"}, []string{"ba", "na"}},
+		{Spanish, "año", []string{"añ"}, []string{"ño"}},
+		{German, "größe", []string{"öß"}, []string{"rö", "ße"}},
+	}
+
+	for _, test := range tests {
+		if result := test.lang.vowelConsonantBoundaries(test.word); !reflect.DeepEqual(result, test.vc) {
+			t.Errorf("%s.vowelConsonantBoundaries(%q) = %v; want %v", test.lang.Name, test.word, result, test.vc)
+		}
+		if result := test.lang.consonantVowelBoundaries(test.word); !reflect.DeepEqual(result, test.cv) {
+			t.Errorf("%s.consonantVowelBoundaries(%q) = %v; want %v", test.lang.Name, test.word, result, test.cv)
+		}
+	}
+}
+
+func TestLanguagePrefixesAndSuffixes(t *testing.T) {
+	words := map[string]int64{"niño": 10, "año": 5}
+	prefixes, suffixes := Spanish.PrefixesAndSuffixes(words)
+
+	for _, prefix := range []string{"ni", "niñ", "añ"} {
+		if !prefixes[prefix] {
+			t.Errorf("Spanish.PrefixesAndSuffixes(%v) is missing prefix %q", words, prefix)
+		}
+	}
+	for _, suffix := range []string{"ño", "iño"} {
+		if !suffixes[suffix] {
+			t.Errorf("Spanish.PrefixesAndSuffixes(%v) is missing suffix %q", words, suffix)
+		}
+	}
+	if len(prefixes) != 3 || len(suffixes) != 2 {
+		t.Errorf("Spanish.PrefixesAndSuffixes(%v) = %v, %v; want 3 prefixes and 2 suffixes", words, prefixes, suffixes)
+	}
+}
+
+func TestLanguageAntiPrefixes(t *testing.T) {
+	words := map[string]int64{"niño": 10}
+	antiPrefixes := Spanish.AntiPrefixes(words)
+
+	n := len(Spanish.Alphabet)
+	if want := n*n + n*n*n - 2; len(antiPrefixes) != want {
+		t.Errorf("len(Spanish.AntiPrefixes(%v)) = %d; want %d", words, len(antiPrefixes), want)
+	}
+	if antiPrefixes["ni"] || antiPrefixes["niñ"] {
+		t.Errorf("Spanish.AntiPrefixes(%v) includes a prefix of niño", words)
+	}
+	if !antiPrefixes["ññ"] {
+		t.Errorf("Spanish.AntiPrefixes(%v) is missing %q", words, "ññ")
+	}
+}
+
+func TestLanguageEncodeWord(t *testing.T) {
+	cipher := Cipher{"n": "ñ", "ñ": "n", "a": "á", "o": "ö", "ß": "s"}
+
+	tests := []struct {
+		lang     Language
+		word     string
+		expected string
+	}{
+		{Spanish, "niño", "ñinö"},
+		{Spanish, "Niño", "Ñinö"},
+		{Spanish, "ÑANDÚ", "NÁÑDÚ"},
+		{German, "Maß", "Más"},
+	}
+
+	for _, test := range tests {
+		result := test.lang.encodeWord(test.word, cipher)
+		if result != test.expected {
+			t.Errorf("%s.encodeWord(%q) = %q; want %q", test.lang.Name, test.word, result, test.expected)
+		}
+	}
+}
+
+func TestLanguageCaseRules(t *testing.T) {
+	turkish := Language{
+		Name:     "Turkish",
+		Alphabet: []rune("abcçdefgğhıijklmnoöprsştuüvyz"),
+		Vowels:   []rune("aeıioöuü"),
+		Case:     unicode.TurkishCase,
+	}
+	cipher := Cipher{"i": "ı", "ı": "i"}
+
+	if result := turkish.encodeWord("İl", cipher); result != "Il" {
+		t.Errorf("Turkish.encodeWord(%q) = %q; want %q", "İl", result, "Il")
+	}
+}
+
+func TestGenerateRandomCipherSimpleLanguage(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, lang := range []Language{English, Spanish, German} {
+		cipher := generateRandomCipherSimple(r, lang)
+		if len(cipher) != len(lang.Alphabet) {
+			t.Errorf("generateRandomCipherSimple(%s) has %d keys; want %d", lang.Name, len(cipher), len(lang.Alphabet))
+		}
+
+		values := make(map[string]bool)
+		for _, letter := range lang.Letters() {
+			value, ok := cipher[letter]
+			if !ok {
+				t.Errorf("generateRandomCipherSimple(%s) is missing key %q", lang.Name, letter)
+			}
+			values[value] = true
+		}
+		if len(values) != len(lang.Alphabet) {
+			t.Errorf("generateRandomCipherSimple(%s) maps %d letters to %d values", lang.Name, len(lang.Alphabet), len(values))
+		}
+	}
+}
+
+func TestDictionaryLanguageDefault(t *testing.T) {
+	words := map[string]int64{"street": 10, "banana": 5}
+	dict := NewDictionary(words, English)
+	dict.Language = Language{}
+
+	if got, want := englishPattern("street", dict), englishPattern("street", NewDictionary(words, English)); got != want {
+		t.Errorf("englishPattern with the zero Language = %d; want %d (English)", got, want)
+	}
+}
@@ -2,55 +2,242 @@ package sifo
 
 import (
 	"bufio"
+	"compress/gzip"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 )
 
+// LoadWords reads a word list from a file, such as words.csv or words.txt, in the format given by its extension (see
+// FormatFromFilename), curated by its overlay file if it has one (see OverlayFilename). It panics if the file cannot
+// be read; use ReadWords to handle errors and see which lines were skipped.
 func LoadWords(filename string) map[string]int64 {
+	overlay, err := loadOverlayFor(filename)
+	if err != nil {
+		panic(err)
+	}
+
 	file, err := os.Open(filename)
 	if err != nil {
 		panic(err)
 	}
 	defer file.Close()
 
-	words := make(map[string]int64)
-	scanner := bufio.NewScanner(file)
-	i := 0
-	for scanner.Scan() {
-		i++
-		if i == 1 {
-			continue // skip first line
+	words, err := ReadWords(file, ReadOptions{Format: FormatFromFilename(filename), Overlay: overlay})
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Loaded %d words\n", len(words))
+	return words
+}
+
+// HeaderMode says whether the first line of a word list is a header.
+type HeaderMode int
+
+const (
+	HeaderAuto    HeaderMode = iota // a header if its count column is not a number
+	HeaderPresent                   // always a header
+	HeaderAbsent                    // never a header
+)
+
+// ReadOptions control how ReadWords reads a word list.
+type ReadOptions struct {
+	Format  Format
+	Header  HeaderMode  // CSV and TSV only
+	Report  *ReadReport // optional; filled in with the header and skipped lines
+	Overlay Overlay     // optional; applied to the words read
+}
+
+// ReadReport describes what ReadWords did not load as words.
+type ReadReport struct {
+	Format  Format   // the format the list was read as
+	Header  []string // nil if there was no header
+	Skipped []SkippedLine
+}
+
+// SkippedLine is a line of a word list that was not loaded, and why.
+type SkippedLine struct {
+	Line   int
+	Text   string
+	Reason string
+}
+
+func (s SkippedLine) String() string {
+	return fmt.Sprintf("line %d: %s (%q)", s.Line, s.Reason, s.Text)
+}
+
+// ReadWords reads a word list in any of the supported formats (see Format), gzip-compressed or not. When
+// opts.Format is FormatAuto, the format is sniffed from the content. Malformed lines, such as those with a missing
+// word or a count that is not an integer, and repeats of a word already read are skipped and listed in opts.Report.
+// Only errors reading r are returned.
+func ReadWords(r io.Reader, opts ReadOptions) (map[string]int64, error) {
+	report := opts.Report
+	if report == nil {
+		report = &ReadReport{}
+	}
+
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		br = bufio.NewReader(gz)
+	}
+
+	format := opts.Format
+	if format == FormatAuto {
+		format = sniffFormat(br)
+	}
+	report.Format = format
+
+	list := newWordList(report)
+	var err error
+	switch format {
+	case FormatCSV:
+		err = readDelimited(br, ',', opts.Header, list)
+	case FormatTSV:
+		err = readDelimited(br, '\t', opts.Header, list)
+	case FormatText:
+		err = readRanked(br, list)
+	case FormatJSON:
+		err = readJSON(br, list)
+	case FormatJSONLines:
+		err = readJSONLines(br, list)
+	default:
+		err = fmt.Errorf("unknown word list format %d", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Overlay != nil {
+		return opts.Overlay.Apply(list.words), nil
+	}
+	return list.words, nil
+}
+
+// wordList collects the words of a list being read, skipping malformed and repeated entries.
+type wordList struct {
+	words      map[string]int64
+	firstLines map[string]int
+	report     *ReadReport
+}
+
+func newWordList(report *ReadReport) *wordList {
+	return &wordList{
+		words:      make(map[string]int64),
+		firstLines: make(map[string]int),
+		report:     report,
+	}
+}
+
+func (l *wordList) skip(line int, text, reason string) {
+	l.report.Skipped = append(l.report.Skipped, SkippedLine{Line: line, Text: text, Reason: reason})
+}
+
+func (l *wordList) add(line int, text, word string, count int64) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		l.skip(line, text, "missing word")
+		return
+	}
+	if firstLine, ok := l.firstLines[word]; ok {
+		l.skip(line, text, fmt.Sprintf("duplicate of line %d", firstLine))
+		return
+	}
+
+	l.words[word] = count
+	l.firstLines[word] = line
+}
+
+// readDelimited reads "word,count" records separated by comma. Quoted fields are supported, extra fields are
+// ignored, and a header is detected according to header.
+func readDelimited(r io.Reader, comma rune, header HeaderMode, list *wordList) error {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.LazyQuotes = comma == '\t'
+
+	first := true
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
 		}
-		line := scanner.Text()
-		parts := strings.Split(line, ",")
-		if len(parts) < 2 {
-			continue // skip lines that don't have at least 2 parts
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			list.skip(parseErr.StartLine, "", parseErr.Err.Error())
+			first = false
+			continue
 		}
-		value, err := strconv.ParseInt(parts[1], 10, 64)
 		if err != nil {
-			continue // skip lines where the second part is not a valid int64
+			return err
 		}
-		words[parts[0]] = value
-	}
-	if err := scanner.Err(); err != nil {
-		panic(err)
+
+		if first {
+			first = false
+			if isHeader(record, header) {
+				list.report.Header = record
+				continue
+			}
+		}
+
+		line, _ := reader.FieldPos(0)
+		text := strings.Join(record, string(comma))
+		if len(record) < 2 {
+			list.skip(line, text, "missing count")
+			continue
+		}
+		count, err := strconv.ParseInt(strings.TrimSpace(record[1]), 10, 64)
+		if err != nil {
+			list.skip(line, text, "count is not an integer")
+			continue
+		}
+		list.add(line, text, record[0], count)
 	}
 
-	fmt.Printf("Loaded %d words\n", len(words))
-	return words
+	return nil
+}
+
+// isHeader reports whether the first record of a word list is a header.
+func isHeader(record []string, mode HeaderMode) bool {
+	switch mode {
+	case HeaderPresent:
+		return true
+	case HeaderAbsent:
+		return false
+	}
+	if len(record) < 2 {
+		return false
+	}
+	_, err := strconv.ParseInt(strings.TrimSpace(record[1]), 10, 64)
+	return err != nil
 }
 
 func CreatePartialWordDictionary(words map[string]int64) map[string]int64 {
+	return English.CreatePartialWordDictionary(words)
+}
+
+// CreatePartialWordDictionary counts every substring of every word, weighted by the word's count. Substrings are
+// taken by letter, so a multi-byte letter is never split.
+func (l Language) CreatePartialWordDictionary(words map[string]int64) map[string]int64 {
 	partialWords := make(map[string]int64)
 
 	for word, count := range words {
-		length := len(word)
+		sp := newSpelling(word)
+		length := sp.len()
 		for i := 0; i < length; i++ {
 			for j := i + 1; j <= length; j++ {
-				substr := word[i:j]
+				substr := sp.slice(i, j)
 				partialWords[substr] += count
 			}
 		}
@@ -64,27 +251,24 @@ func CreatePartialWordDictionary(words map[string]int64) map[string]int64 {
 // "vc" because it has a vowel followed by a consonant. "street" would be "cvc" because it has a consonant group
 // followed by a vowel group followed by a consonant group.
 func WordPatterns(words map[string]int64) map[string]bool {
-	patterns := make(map[string]bool)
-
-	for word := range words {
-		pattern := wordPattern(word)
-		patterns[pattern] = true
-	}
+	return English.WordPatterns(words)
+}
 
-	return patterns
+// WordPatterns returns the vowel and consonant patterns of the words, such as "cvc" for "street".
+func (l Language) WordPatterns(words map[string]int64) map[string]bool {
+	return supported(l.WordPatternCounts(words), 0)
 }
 
 // wordPattern breaks down a word into a pattern of vowels and consonants. 1 or more vowels is a vowel group and
 // 1 or more consonants is a consonant group. For example, "at" would be "vc" because it has a vowel followed by a
 // consonant. "street" would be "cvc" because it has a consonant group followed by a vowel group followed by a
 // consonant group.
-func wordPattern(word string) string {
-	vowels := "aeiouy"
+func (l Language) wordPattern(word string) string {
 	pattern := ""
 	var lastCharType rune
 
 	for _, char := range word {
-		if strings.ContainsRune(vowels, char) {
+		if l.IsVowel(char) {
 			if lastCharType != 'v' {
 				pattern += "v"
 				lastCharType = 'v'
@@ -100,76 +284,45 @@ func wordPattern(word string) string {
 	return pattern
 }
 
-// ListVowelGroups returns a map of vowel groups found in the words. A vowel group is defined as 1 or more vowels.
-// For now, this simply returns a map of all the vowel groups found in the words. This could be expanded to return
-// counts of each vowel group.
+// VowelGroups returns the vowel groups found in the words. A vowel group is a run of 1 or more vowels.
 func VowelGroups(words map[string]int64) map[string]bool {
-	vowelGroupsMap := make(map[string]bool)
-
-	for word := range words {
-		groups := vowelGroups(word)
-		for _, group := range groups {
-			vowelGroupsMap[group] = true
-		}
-	}
+	return English.VowelGroups(words)
+}
 
-	return vowelGroupsMap
+// VowelGroups returns the runs of vowels found in the words.
+func (l Language) VowelGroups(words map[string]int64) map[string]bool {
+	return supported(l.VowelGroupCounts(words), 0)
 }
 
 // vowelGroups returns a slice of unique vowel groups found in the word. A vowel group is defined as 1 or more vowels.
-func vowelGroups(word string) []string {
-	vowels := "aeiouy"
-	var groups []string
-	var currentGroup string
-	uniqueGroups := make(map[string]bool)
-
-	for _, char := range word {
-		if strings.ContainsRune(vowels, char) {
-			currentGroup += string(char)
-		} else {
-			if currentGroup != "" {
-				if !uniqueGroups[currentGroup] {
-					groups = append(groups, currentGroup)
-					uniqueGroups[currentGroup] = true
-				}
-				currentGroup = ""
-			}
-		}
-	}
-
-	if currentGroup != "" && !uniqueGroups[currentGroup] {
-		groups = append(groups, currentGroup)
-		uniqueGroups[currentGroup] = true
-	}
-
-	return groups
+func (l Language) vowelGroups(word string) []string {
+	return l.groups(word, true)
 }
 
-// ListConsonantGroups returns a map of consonant groups found in the words. A consonant group is defined as 1 or more consonants.
-// For now, this simply returns a map of all the consonant groups found in the words. This could be expanded to return
-// counts of each consonant group.
+// ConsonantGroups returns the consonant groups found in the words. A consonant group is a run of 1 or more
+// consonants.
 func ConsonantGroups(words map[string]int64) map[string]bool {
-	consonantGroupsMap := make(map[string]bool)
-
-	for word := range words {
-		groups := consonantGroups(word)
-		for _, group := range groups {
-			consonantGroupsMap[group] = true
-		}
-	}
+	return English.ConsonantGroups(words)
+}
 
-	return consonantGroupsMap
+// ConsonantGroups returns the runs of consonants found in the words.
+func (l Language) ConsonantGroups(words map[string]int64) map[string]bool {
+	return supported(l.ConsonantGroupCounts(words), 0)
 }
 
 // consonantGroups returns a slice of unique consonant groups found in the word. A consonant group is defined as 1 or more consonants.
-func consonantGroups(word string) []string {
-	vowels := "aeiouy"
+func (l Language) consonantGroups(word string) []string {
+	return l.groups(word, false)
+}
+
+// groups returns the unique runs of vowels (or of consonants) in the word, in the order they first appear.
+func (l Language) groups(word string, vowels bool) []string {
 	var groups []string
 	var currentGroup string
 	uniqueGroups := make(map[string]bool)
 
 	for _, char := range word {
-		if !strings.ContainsRune(vowels, char) {
+		if l.IsVowel(char) == vowels {
 			currentGroup += string(char)
 		} else {
 			if currentGroup != "" {
@@ -191,77 +344,52 @@ func consonantGroups(word string) []string {
 }
 
 func VowelConsonantBoundaries(words map[string]int64) map[string]bool {
-	boundaries := make(map[string]bool)
-
-	for word := range words {
-		boundary := vowelConsonantBoundaries(word)
-		for _, b := range boundary {
-			boundaries[b] = true
-		}
-	}
+	return English.VowelConsonantBoundaries(words)
+}
 
-	return boundaries
+// VowelConsonantBoundaries returns the letter pairs where a vowel is followed by a consonant in the words.
+func (l Language) VowelConsonantBoundaries(words map[string]int64) map[string]bool {
+	return supported(l.VowelConsonantBoundaryCounts(words), 0)
 }
 
 // vowelConsonantBoundaries returns a slice of 2-length strings that represent the unique boundaries between vowel
 // groups and consonant groups in the word. For example, "apple" would return ["ap"] because it is the only vowel
 // group followed by a consonant group, "street" would return ["et"], "banana" would return ["an"], and
 // "beautiful" would return ["ut", "if", "ul"].
-func vowelConsonantBoundaries(word string) []string {
-	vowels := "aeiouy"
-	var boundaries []string
-	uniqueBoundaries := make(map[string]bool)
-
-	for i := 0; i < len(word)-1; i++ {
-		currentChar := word[i]
-		nextChar := word[i+1]
-
-		currentIsVowel := strings.ContainsRune(vowels, rune(currentChar))
-		nextIsVowel := strings.ContainsRune(vowels, rune(nextChar))
-
-		if currentIsVowel && !nextIsVowel {
-			boundary := string(currentChar) + string(nextChar)
-			if !uniqueBoundaries[boundary] {
-				boundaries = append(boundaries, boundary)
-				uniqueBoundaries[boundary] = true
-			}
-		}
-	}
-
-	return boundaries
+func (l Language) vowelConsonantBoundaries(word string) []string {
+	return l.boundaries(word, true)
 }
 
 func ConsonantVowelBoundaries(words map[string]int64) map[string]bool {
-	boundaries := make(map[string]bool)
-
-	for word := range words {
-		boundary := consonantVowelBoundaries(word)
-		for _, b := range boundary {
-			boundaries[b] = true
-		}
-	}
+	return English.ConsonantVowelBoundaries(words)
+}
 
-	return boundaries
+// ConsonantVowelBoundaries returns the letter pairs where a consonant is followed by a vowel in the words.
+func (l Language) ConsonantVowelBoundaries(words map[string]int64) map[string]bool {
+	return supported(l.ConsonantVowelBoundaryCounts(words), 0)
 }
 
 // consonantVowelBoundaries returns a slice of 2-length strings that represent the unique boundaries between consonant
 // groups and vowel groups in the word. For example, "apple" would return ["le"] because it is the only consonant
 // group ("ppl") followed by a vowel group ("e"), "street" would return ["re"], "banana" would return ["ba", "na"], and
 // "beautiful" would return ["be", "ti", "fu"].
-func consonantVowelBoundaries(word string) []string {
-	vowels := "aeiouy"
+func (l Language) consonantVowelBoundaries(word string) []string {
+	return l.boundaries(word, false)
+}
+
+// boundaries returns the unique 2-letter pairs where a vowel is followed by a consonant (or, if fromVowel is false,
+// where a consonant is followed by a vowel).
+func (l Language) boundaries(word string, fromVowel bool) []string {
 	var boundaries []string
 	uniqueBoundaries := make(map[string]bool)
 
-	for i := 0; i < len(word)-1; i++ {
-		currentChar := word[i]
-		nextChar := word[i+1]
-
-		currentIsVowel := strings.ContainsRune(vowels, rune(currentChar))
-		nextIsVowel := strings.ContainsRune(vowels, rune(nextChar))
+	sp := newSpelling(word)
+	for i := 0; i < sp.len()-1; i++ {
+		currentIsVowel := l.IsVowel(sp.at(i))
+		nextIsVowel := l.IsVowel(sp.at(i + 1))
 
-		if !currentIsVowel && nextIsVowel {
-			boundary := string(currentChar) + string(nextChar)
+		if currentIsVowel == fromVowel && nextIsVowel != fromVowel {
+			boundary := sp.slice(i, i+2)
 			if !uniqueBoundaries[boundary] {
 				boundaries = append(boundaries, boundary)
 				uniqueBoundaries[boundary] = true
@@ -273,112 +401,48 @@ func consonantVowelBoundaries(word string) []string {
 }
 
 func PrefixesAndSuffixes(words map[string]int64) (map[string]bool, map[string]bool) {
-	prefixes := make(map[string]int64)
-	suffixes := make(map[string]int64)
-
-	for word, count := range words {
-		length := len(word)
-		if length >= 3 {
-			prefix := word[:2]
-			suffix := word[length-2:]
-			prefixes[prefix] += count
-			suffixes[suffix] += count
-		}
-		if length >= 4 {
-			prefix := word[:3]
-			suffix := word[length-3:]
-			prefixes[prefix] += count
-			suffixes[suffix] += count
-		}
-	}
-
-	// Filter prefixes and suffixes based on the specified thresholds
-	filteredPrefixes := make(map[string]bool)
-	filteredSuffixes := make(map[string]bool)
-
-	for prefix, _ := range prefixes {
-		filteredPrefixes[prefix] = true
-	}
-
-	for suffix, _ := range suffixes {
-		filteredSuffixes[suffix] = true
-	}
+	return English.PrefixesAndSuffixes(words)
+}
 
-	return filteredPrefixes, filteredSuffixes
+// PrefixesAndSuffixes returns the 2 and 3 letter prefixes and suffixes of the words.
+func (l Language) PrefixesAndSuffixes(words map[string]int64) (map[string]bool, map[string]bool) {
+	prefixes, suffixes := l.PrefixAndSuffixCounts(words)
+	return supported(prefixes, 0), supported(suffixes, 0)
 }
 
 func Middles(words map[string]int64) map[string]bool {
-	middleSubstrings := make(map[string]int64)
-
-	for word, count := range words {
-		length := len(word)
-		if length >= 4 {
-			for i := 1; i < length-2; i++ {
-				substr := word[i : i+2]
-				middleSubstrings[substr] += count
-			}
-		}
-		if length >= 5 {
-			for i := 1; i < length-3; i++ {
-				substr := word[i : i+3]
-				middleSubstrings[substr] += count
-			}
-		}
-		if length >= 6 {
-			for i := 1; i < length-4; i++ {
-				substr := word[i : i+4]
-				middleSubstrings[substr] += count
-			}
-		}
-	}
-
-	filteredMiddles := make(map[string]bool)
-
-	for middle, _ := range middleSubstrings {
-		filteredMiddles[middle] = true
-	}
+	return English.Middles(words)
+}
 
-	return filteredMiddles
+// Middles returns the 2, 3 and 4 letter runs found inside the words, away from either end.
+func (l Language) Middles(words map[string]int64) map[string]bool {
+	return supported(l.MiddleCounts(words), 0)
 }
 
 func AntiPrefixes(words map[string]int64) map[string]bool {
-	// Create a slice of all possible 2 and 3 letter combinations
-	var combinations []string
-	letters := "abcdefghijklmnopqrstuvwxyz"
-
-	// Generate 2-letter combinations
-	for _, first := range letters {
-		for _, second := range letters {
-			combinations = append(combinations, string([]rune{first, second}))
-		}
-	}
-
-	// Generate 3-letter combinations
-	for _, first := range letters {
-		for _, second := range letters {
-			for _, third := range letters {
-				combinations = append(combinations, string([]rune{first, second, third}))
-			}
-		}
-	}
+	return English.AntiPrefixes(words)
+}
 
+// AntiPrefixes returns the 2 and 3 letter combinations of the alphabet that no word starts with.
+func (l Language) AntiPrefixes(words map[string]int64) map[string]bool {
 	// Create a map to store the prefixes that occur in words
 	prefixes := make(map[string]bool)
 
 	// Iterate through the words and mark the prefixes that occur
 	for word := range words {
-		length := len(word)
+		sp := newSpelling(word)
+		length := sp.len()
 		if length >= 3 {
-			prefixes[word[:2]] = true
+			prefixes[sp.slice(0, 2)] = true
 		}
 		if length >= 4 {
-			prefixes[word[:3]] = true
+			prefixes[sp.slice(0, 3)] = true
 		}
 	}
 
 	// Eliminate any 2 or 3 letter prefixes that occur in words
 	antiPrefixes := make(map[string]bool)
-	for _, combination := range combinations {
+	for _, combination := range l.combinations() {
 		if !prefixes[combination] {
 			antiPrefixes[combination] = true
 		}
@@ -388,43 +452,29 @@ func AntiPrefixes(words map[string]int64) map[string]bool {
 }
 
 func AntiSuffixes(words map[string]int64) map[string]bool {
-	// Create a slice of all possible 2 and 3 letter combinations
-	var combinations []string
-	letters := "abcdefghijklmnopqrstuvwxyz"
-
-	// Generate 2-letter combinations
-	for _, first := range letters {
-		for _, second := range letters {
-			combinations = append(combinations, string([]rune{first, second}))
-		}
-	}
-
-	// Generate 3-letter combinations
-	for _, first := range letters {
-		for _, second := range letters {
-			for _, third := range letters {
-				combinations = append(combinations, string([]rune{first, second, third}))
-			}
-		}
-	}
+	return English.AntiSuffixes(words)
+}
 
+// AntiSuffixes returns the 2 and 3 letter combinations of the alphabet that no word ends with.
+func (l Language) AntiSuffixes(words map[string]int64) map[string]bool {
 	// Create a map to store the suffixes that occur in words
 	suffixes := make(map[string]bool)
 
 	// Iterate through the words and mark the suffixes that occur
 	for word := range words {
-		length := len(word)
+		sp := newSpelling(word)
+		length := sp.len()
 		if length >= 3 {
-			suffixes[word[length-2:]] = true
+			suffixes[sp.slice(length-2, length)] = true
 		}
 		if length >= 4 {
-			suffixes[word[length-3:]] = true
+			suffixes[sp.slice(length-3, length)] = true
 		}
 	}
 
 	// Eliminate any 2 or 3 letter suffixes that occur in words
 	antiSuffixes := make(map[string]bool)
-	for _, combination := range combinations {
+	for _, combination := range l.combinations() {
 		if !suffixes[combination] {
 			antiSuffixes[combination] = true
 		}
@@ -434,47 +484,33 @@ func AntiSuffixes(words map[string]int64) map[string]bool {
 }
 
 func AntiMiddles(words map[string]int64) map[string]bool {
-	// Create a slice of all possible 2 and 3 letter combinations
-	var combinations []string
-	letters := "abcdefghijklmnopqrstuvwxyz"
-
-	// Generate 2-letter combinations
-	for _, first := range letters {
-		for _, second := range letters {
-			combinations = append(combinations, string([]rune{first, second}))
-		}
-	}
-
-	// Generate 3-letter combinations
-	for _, first := range letters {
-		for _, second := range letters {
-			for _, third := range letters {
-				combinations = append(combinations, string([]rune{first, second, third}))
-			}
-		}
-	}
+	return English.AntiMiddles(words)
+}
 
+// AntiMiddles returns the 2 and 3 letter combinations of the alphabet found inside no word.
+func (l Language) AntiMiddles(words map[string]int64) map[string]bool {
 	// Create a map to store the middles that occur in words
 	middles := make(map[string]bool)
 
 	// Iterate through the words and mark the middles that occur
 	for word := range words {
-		length := len(word)
+		sp := newSpelling(word)
+		length := sp.len()
 		if length >= 4 {
 			for i := 1; i <= length-3; i++ {
-				middles[word[i:i+2]] = true
+				middles[sp.slice(i, i+2)] = true
 			}
 		}
 		if length >= 5 {
 			for i := 1; i <= length-4; i++ {
-				middles[word[i:i+3]] = true
+				middles[sp.slice(i, i+3)] = true
 			}
 		}
 	}
 
 	// Eliminate any 2 or 3 letter middles that occur in words
 	antiMiddles := make(map[string]bool)
-	for _, combination := range combinations {
+	for _, combination := range l.combinations() {
 		if !middles[combination] {
 			antiMiddles[combination] = true
 		}
@@ -483,27 +519,30 @@ func AntiMiddles(words map[string]int64) map[string]bool {
 	return antiMiddles
 }
 
-func WriteCSV(partialWords map[string]int64, filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+// combinations returns every 2 and 3 letter combination of the language's alphabet.
+func (l Language) combinations() []string {
+	var combinations []string
 
-	// Write header
-	if err := writer.Write([]string{"Partial Word", "Count"}); err != nil {
-		return err
+	// Generate 2-letter combinations
+	for _, first := range l.Alphabet {
+		for _, second := range l.Alphabet {
+			combinations = append(combinations, string([]rune{first, second}))
+		}
 	}
 
-	// Write data
-	for k, v := range partialWords {
-		if err := writer.Write([]string{k, strconv.FormatInt(v, 10)}); err != nil {
-			return err
+	// Generate 3-letter combinations
+	for _, first := range l.Alphabet {
+		for _, second := range l.Alphabet {
+			for _, third := range l.Alphabet {
+				combinations = append(combinations, string([]rune{first, second, third}))
+			}
 		}
 	}
 
-	return nil
+	return combinations
+}
+
+// WriteCSV writes a partial word dictionary to a file. It is SaveCounts under its original name.
+func WriteCSV(partialWords map[string]int64, filename string) error {
+	return SaveCounts(filename, partialWords)
 }
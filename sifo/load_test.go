@@ -1,8 +1,11 @@
 package sifo
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestWordPattern(t *testing.T) {
@@ -27,7 +30,7 @@ func TestWordPattern(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := wordPattern(test.word)
+		result := English.wordPattern(test.word)
 		if result != test.expected {
 			t.Errorf("wordPattern(%q) = %v; want %v", test.word, result, test.expected)
 		}
@@ -53,7 +56,7 @@ func TestVowelGroups(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := vowelGroups(test.word)
+		result := English.vowelGroups(test.word)
 		if !reflect.DeepEqual(result, test.expected) {
 			t.Errorf("vowelGroups(%q) = %v; want %v", test.word, result, test.expected)
 		}
@@ -79,7 +82,7 @@ func TestConsonantGroups(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := consonantGroups(test.word)
+		result := English.consonantGroups(test.word)
 		if !reflect.DeepEqual(result, test.expected) {
 			t.Errorf("consonantGroups(%q) = %v; want %v", test.word, result, test.expected)
 		}
@@ -105,7 +108,7 @@ func TestVowelConsonantBoundaries(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := vowelConsonantBoundaries(test.word)
+		result := English.vowelConsonantBoundaries(test.word)
 		if !reflect.DeepEqual(result, test.expected) {
 			t.Errorf("vowelConsonantBoundaries(%q) = %v; want %v", test.word, result, test.expected)
 		}
@@ -132,9 +135,97 @@ func TestConsonantVowelBoundaries(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := consonantVowelBoundaries(test.word)
+		result := English.consonantVowelBoundaries(test.word)
 		if !reflect.DeepEqual(result, test.expected) {
 			t.Errorf("consonantVowelBoundaries(%q) = %v; want %v", test.word, result, test.expected)
 		}
 	}
 }
+
+func TestReadWords(t *testing.T) {
+	input := `Word,Count Per Billion
+the,56271872
+"of",33950064
+"rock, paper",1000
+and
+,500
+to,lots
+the,25956096
+a,10,extra
+`
+
+	var report ReadReport
+	words, err := ReadWords(strings.NewReader(input), ReadOptions{Report: &report})
+	if err != nil {
+		t.Fatalf("ReadWords() error = %v", err)
+	}
+
+	expectedWords := map[string]int64{
+		"the":         56271872,
+		"of":          33950064,
+		"rock, paper": 1000,
+		"a":           10,
+	}
+	if !reflect.DeepEqual(words, expectedWords) {
+		t.Errorf("ReadWords() = %v; want %v", words, expectedWords)
+	}
+
+	expectedHeader := []string{"Word", "Count Per Billion"}
+	if !reflect.DeepEqual(report.Header, expectedHeader) {
+		t.Errorf("ReadWords() header = %v; want %v", report.Header, expectedHeader)
+	}
+
+	expectedSkipped := []SkippedLine{
+		{Line: 5, Text: "and", Reason: "missing count"},
+		{Line: 6, Text: ",500", Reason: "missing word"},
+		{Line: 7, Text: "to,lots", Reason: "count is not an integer"},
+		{Line: 8, Text: "the,25956096", Reason: "duplicate of line 2"},
+	}
+	if !reflect.DeepEqual(report.Skipped, expectedSkipped) {
+		t.Errorf("ReadWords() skipped = %v; want %v", report.Skipped, expectedSkipped)
+	}
+}
+
+func TestReadWordsHeader(t *testing.T) {
+	tests := []struct {
+		input    string
+		mode     HeaderMode
+		expected int
+	}{
+		{"the,100\nof,50\n", HeaderAuto, 2},        // No header detected
+		{"Word,Count\nthe,100\n", HeaderAuto, 1},   // Header detected
+		{"the,100\nof,50\n", HeaderPresent, 1},     // First line forced to be a header
+		{"word,count\nthe,100\n", HeaderAbsent, 1}, // Header read as a malformed line
+	}
+
+	for _, test := range tests {
+		words, err := ReadWords(strings.NewReader(test.input), ReadOptions{Header: test.mode})
+		if err != nil {
+			t.Fatalf("ReadWords(%q) error = %v", test.input, err)
+		}
+		if len(words) != test.expected {
+			t.Errorf("ReadWords(%q) loaded %d words; want %d", test.input, len(words), test.expected)
+		}
+	}
+}
+
+func TestReadWordsMalformedQuotes(t *testing.T) {
+	var report ReadReport
+	words, err := ReadWords(strings.NewReader("the,100\nbad\"quote,5\nof,50\n"), ReadOptions{Report: &report})
+	if err != nil {
+		t.Fatalf("ReadWords() error = %v", err)
+	}
+	if len(words) != 2 {
+		t.Errorf("ReadWords() loaded %d words; want 2", len(words))
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].Line != 2 {
+		t.Errorf("ReadWords() skipped = %v; want line 2", report.Skipped)
+	}
+}
+
+func TestReadWordsError(t *testing.T) {
+	_, err := ReadWords(iotest.ErrReader(errors.New("connection reset")), ReadOptions{})
+	if err == nil {
+		t.Errorf("ReadWords() error = nil; want the reader's error")
+	}
+}
@@ -0,0 +1,596 @@
+package sifo
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// rawElements are the HTML elements whose content is code or otherwise not prose, so it is never encoded.
+var rawElements = map[string]bool{
+	"code":     true,
+	"kbd":      true,
+	"pre":      true,
+	"samp":     true,
+	"script":   true,
+	"style":    true,
+	"textarea": true,
+}
+
+// markup collects the encoding of a document, keeping markup as it is and encoding the text between it. Text is
+// held until the next markup so that a word is encoded as a whole.
+type markup struct {
+	lang   Language
+	cipher Cipher
+	labels map[string]bool // Markdown link reference labels, lower case
+	out    strings.Builder
+	text   strings.Builder
+}
+
+func (m *markup) plain(s string) {
+	m.text.WriteString(s)
+}
+
+func (m *markup) verbatim(s string) {
+	m.flush()
+	m.out.WriteString(s)
+}
+
+func (m *markup) flush() {
+	if m.text.Len() > 0 {
+		m.out.WriteString(m.lang.encodeRuns(m.text.String(), m.cipher))
+		m.text.Reset()
+	}
+}
+
+func (m *markup) String() string {
+	m.flush()
+	return m.out.String()
+}
+
+// EncodeHTML encodes the text of an English HTML document like EncodeText and leaves its markup as it is.
+func EncodeHTML(text string, cipher Cipher) string {
+	return English.EncodeHTML(text, cipher)
+}
+
+// EncodeHTML encodes the text of an HTML document like EncodeText and leaves tags, their attributes, comments,
+// character references such as "&amp;", and the content of code, pre, script, style and similar elements as they
+// are, so that the encoded page still renders and its links still work.
+func (l Language) EncodeHTML(text string, cipher Cipher) string {
+	m := &markup{lang: l, cipher: cipher}
+	for i := 0; i < len(text); {
+		if end := htmlEnd(text, i); end > i {
+			m.verbatim(text[i:end])
+			i = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		m.plain(text[i : i+size])
+		i += size
+	}
+	return m.String()
+}
+
+// htmlEnd returns the end of the tag, comment or character reference starting at text[i], taking in the content
+// and end tag of a raw element, or i if there is none there.
+func htmlEnd(text string, i int) int {
+	switch text[i] {
+	case '&':
+		return entityEnd(text, i)
+	case '<':
+		if strings.HasPrefix(text[i:], "<!--") {
+			return indexEnd(text, i+len("<!--"), "-->")
+		}
+		if strings.HasPrefix(text[i:], "<![CDATA[") {
+			return indexEnd(text, i+len("<![CDATA["), "]]>")
+		}
+		end := tagEnd(text, i)
+		if end == i {
+			return i
+		}
+		if name, closing := tagName(text[i:end]); !closing && rawElements[name] && !strings.HasSuffix(text[i:end], "/>") {
+			return rawElementEnd(text, end, name)
+		}
+		return end
+	}
+	return i
+}
+
+// indexEnd returns the end of the first sep in text from i on, or the end of text if there is none.
+func indexEnd(text string, i int, sep string) int {
+	if j := strings.Index(text[i:], sep); j >= 0 {
+		return i + j + len(sep)
+	}
+	return len(text)
+}
+
+// tagEnd returns the end of the tag starting at text[i], such as "<a href="x">", "</p>" or "<!DOCTYPE html>", or i
+// if text[i] does not start a tag. A ">" inside a quoted attribute value does not end the tag.
+func tagEnd(text string, i int) int {
+	j := i + 1
+	if j < len(text) && (text[j] == '/' || text[j] == '!' || text[j] == '?') {
+		j++
+	}
+	if j >= len(text) || !isASCIILetter(text[j]) {
+		return i
+	}
+
+	var quote byte
+	for ; j < len(text); j++ {
+		switch c := text[j]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return j + 1
+		}
+	}
+	return i
+}
+
+// tagName returns the lower case name of a tag and whether it is an end tag.
+func tagName(tag string) (string, bool) {
+	tag = strings.TrimPrefix(tag, "<")
+	closing := strings.HasPrefix(tag, "/")
+	tag = strings.TrimPrefix(tag, "/")
+	end := strings.IndexFunc(tag, func(r rune) bool {
+		return !(r < utf8.RuneSelf && (isASCIILetter(byte(r)) || r >= '0' && r <= '9' || r == '-'))
+	})
+	if end < 0 {
+		end = len(tag)
+	}
+	return strings.ToLower(tag[:end]), closing
+}
+
+// rawElementEnd returns the end of the end tag that closes the element named name whose content starts at text[i],
+// allowing for elements of the same name inside it, or the end of text if it is never closed.
+func rawElementEnd(text string, i int, name string) int {
+	depth := 1
+	for i < len(text) {
+		j := strings.IndexByte(text[i:], '<')
+		if j < 0 {
+			break
+		}
+		i += j
+		end := tagEnd(text, i)
+		if end == i {
+			i++
+			continue
+		}
+		if tag, closing := tagName(text[i:end]); tag == name {
+			if closing {
+				depth--
+			} else if !strings.HasSuffix(text[i:end], "/>") {
+				depth++
+			}
+			if depth == 0 {
+				return end
+			}
+		}
+		i = end
+	}
+	return len(text)
+}
+
+// entityEnd returns the end of the character reference starting at text[i], such as "&amp;", "&#39;" or "&#x2014;",
+// or i if there is none there.
+func entityEnd(text string, i int) int {
+	j := i + 1
+	digits := func(hex bool) int {
+		k := j
+		for k < len(text) && (text[k] >= '0' && text[k] <= '9' || hex && strings.IndexByte("abcdefABCDEF", text[k]) >= 0) {
+			k++
+		}
+		return k
+	}
+
+	var end int
+	switch {
+	case strings.HasPrefix(text[j:], "#x") || strings.HasPrefix(text[j:], "#X"):
+		j += 2
+		end = digits(true)
+	case strings.HasPrefix(text[j:], "#"):
+		j++
+		end = digits(false)
+	case j < len(text) && isASCIILetter(text[j]):
+		end = j
+		for end < len(text) && (isASCIILetter(text[end]) || text[end] >= '0' && text[end] <= '9') {
+			end++
+		}
+	default:
+		return i
+	}
+	if end == j || end >= len(text) || text[end] != ';' || end-i > 32 {
+		return i
+	}
+	return end + 1
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// EncodeMarkdown encodes the text of an English Markdown document like EncodeText and leaves its markup as it is.
+func EncodeMarkdown(text string, cipher Cipher) string {
+	return English.EncodeMarkdown(text, cipher)
+}
+
+// EncodeMarkdown encodes the text of a Markdown document like EncodeText, including the text of links, headings,
+// lists, tables and emphasis, and leaves as they are:
+//   - front matter, fenced and indented code blocks, and code spans;
+//   - link destinations and titles, link reference labels and definitions, footnote labels, and whole images;
+//   - autolinks and bare URLs;
+//   - HTML, as EncodeHTML does, including pre, script and style blocks.
+//
+// The encoded document renders the same, with the same links, apart from its text.
+func (l Language) EncodeMarkdown(text string, cipher Cipher) string {
+	lines := strings.SplitAfter(text, "\n")
+	m := &markup{lang: l, cipher: cipher, labels: referenceLabels(lines)}
+
+	var paragraph strings.Builder
+	endParagraph := func() {
+		m.inline(paragraph.String())
+		paragraph.Reset()
+	}
+
+	i := frontMatterEnd(lines)
+	m.verbatim(strings.Join(lines[:i], ""))
+
+	inList, afterBlank := false, true
+	for i < len(lines) {
+		content := strings.TrimRight(lines[i], "\r\n")
+		trimmed := strings.TrimLeft(content, " \t")
+		indented := strings.HasPrefix(content, "    ") || strings.HasPrefix(content, "\t")
+
+		if trimmed == "" {
+			paragraph.WriteString(lines[i])
+			endParagraph()
+			afterBlank = true
+			i++
+			continue
+		}
+
+		end := i
+		switch {
+		case fence(trimmed) != "":
+			end = fenceEnd(lines, i, fence(trimmed))
+		case indented && afterBlank && !inList:
+			end = i + 1
+			for end < len(lines) && (strings.HasPrefix(lines[end], "    ") || strings.HasPrefix(lines[end], "\t") || strings.TrimSpace(lines[end]) == "") {
+				end++
+			}
+		}
+		if end > i {
+			endParagraph()
+			m.verbatim(strings.Join(lines[i:end], ""))
+			i, afterBlank = end, false
+			continue
+		}
+
+		if end, n := htmlBlockEnd(lines, i, trimmed); !indented && end > i {
+			// Text after the closing tag on its line is prose.
+			endParagraph()
+			m.verbatim(strings.Join(lines[i:end-1], "") + lines[end-1][:n])
+			paragraph.WriteString(lines[end-1][n:])
+			i, afterBlank = end, false
+			continue
+		}
+
+		if label, n := referenceDefinition(trimmed); n > 0 && !indented {
+			endParagraph()
+			n += len(content) - len(trimmed)
+			if strings.HasPrefix(label, "^") {
+				// A footnote's text is prose; only its label is kept.
+				m.verbatim(lines[i][:n])
+				paragraph.WriteString(lines[i][n:])
+			} else {
+				m.verbatim(lines[i])
+			}
+			i, afterBlank = i+1, false
+			continue
+		}
+
+		if !indented {
+			inList = listItem(trimmed) || inList && !afterBlank
+		}
+		paragraph.WriteString(lines[i])
+		i, afterBlank = i+1, false
+	}
+	endParagraph()
+
+	return m.String()
+}
+
+// frontMatterEnd returns the number of lines of YAML front matter at the start of a document, 0 if it has none.
+func frontMatterEnd(lines []string) int {
+	if len(lines) == 0 || strings.TrimRight(lines[0], "\r\n") != "---" {
+		return 0
+	}
+	for i := 1; i < len(lines); i++ {
+		if line := strings.TrimRight(lines[i], "\r\n"); line == "---" || line == "..." {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// fence returns the run of backticks or tildes that opens a fenced code block on the line, or "" if it opens none.
+func fence(trimmed string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(trimmed) - len(strings.TrimLeft(trimmed, c))
+		if n >= 3 && !(c == "`" && strings.Contains(trimmed[n:], "`")) {
+			return trimmed[:n]
+		}
+	}
+	return ""
+}
+
+// fenceEnd returns the line after the one that closes the fenced code block opened on line i with the fence, or the
+// number of lines if it is never closed.
+func fenceEnd(lines []string, i int, fence string) int {
+	for j := i + 1; j < len(lines); j++ {
+		trimmed := strings.TrimSpace(lines[j])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			return j + 1
+		}
+	}
+	return len(lines)
+}
+
+// htmlBlockEnd finds the end of an HTML comment or raw element block starting on line i, such as a pre block of
+// several lines. It returns the line after the one the block ends on and how many bytes of that line the block takes
+// up to and including its closing tag, or i if no block starts there.
+func htmlBlockEnd(lines []string, i int, trimmed string) (int, int) {
+	opening, closing := 0, ""
+	if strings.HasPrefix(trimmed, "<!--") {
+		opening, closing = len("<!--"), "-->"
+	} else if end := tagEnd(trimmed, 0); end > 0 {
+		if name, isEnd := tagName(trimmed[:end]); !isEnd && rawElements[name] && name != "code" && name != "kbd" && name != "samp" {
+			opening, closing = end, "</"+name+">"
+		}
+	}
+	if closing == "" {
+		return i, 0
+	}
+
+	from := len(lines[i]) - len(strings.TrimLeft(lines[i], " \t")) + opening
+	for j := i; j < len(lines); j++ {
+		for k := from; k+len(closing) <= len(lines[j]); k++ {
+			if strings.EqualFold(lines[j][k:k+len(closing)], closing) {
+				return j + 1, k + len(closing)
+			}
+		}
+		from = 0
+	}
+	return len(lines), len(lines[len(lines)-1])
+}
+
+// listItem reports whether a line starts a list item, such as "- item", "* item" or "1. item".
+func listItem(trimmed string) bool {
+	marker := 0
+	switch {
+	case strings.HasPrefix(trimmed, "-"), strings.HasPrefix(trimmed, "*"), strings.HasPrefix(trimmed, "+"):
+		marker = 1
+	default:
+		for marker < len(trimmed) && marker < 9 && trimmed[marker] >= '0' && trimmed[marker] <= '9' {
+			marker++
+		}
+		if marker == 0 || marker >= len(trimmed) || trimmed[marker] != '.' && trimmed[marker] != ')' {
+			return false
+		}
+		marker++
+	}
+	return marker == len(trimmed) || trimmed[marker] == ' ' || trimmed[marker] == '\t'
+}
+
+// referenceDefinition returns the label of the link reference or footnote definition that starts a line, as in
+// "[label]: https://example.com" or "[^1]: text", and the length of the line up to and including its colon, or 0 if
+// the line is not one.
+func referenceDefinition(trimmed string) (string, int) {
+	if !strings.HasPrefix(trimmed, "[") {
+		return "", 0
+	}
+	end := bracketEnd(trimmed, 0)
+	if end < 3 || end >= len(trimmed) || trimmed[end] != ':' {
+		return "", 0
+	}
+	return trimmed[1 : end-1], end + 1
+}
+
+// referenceLabels returns the labels of a document's link reference definitions, in lower case.
+func referenceLabels(lines []string) map[string]bool {
+	labels := make(map[string]bool)
+	for _, line := range lines {
+		if label, n := referenceDefinition(strings.TrimLeft(line, " ")); n > 0 && !strings.HasPrefix(label, "^") {
+			labels[strings.ToLower(label)] = true
+		}
+	}
+	return labels
+}
+
+// inline encodes the text of a paragraph, leaving its inline markup as it is.
+func (m *markup) inline(s string) {
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] < utf8.RuneSelf && unicode.IsPunct(rune(s[i+1])):
+			m.verbatim(s[i : i+2])
+			i += 2
+			continue
+
+		case c == '`':
+			end := codeSpanEnd(s, i)
+			m.verbatim(s[i:end])
+			i = end
+			continue
+
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if end := linkEnd(s, i+1); end > 0 {
+				m.verbatim(s[i:end])
+				i = end
+				continue
+			}
+
+		case c == '[':
+			if end := m.link(s, i); end > i {
+				i = end
+				continue
+			}
+
+		case c == '<' || c == '&':
+			if end := htmlEnd(s, i); end > i {
+				m.verbatim(s[i:end])
+				i = end
+				continue
+			}
+
+		case c == 'h' || c == 'w' || c == 'm':
+			if end := urlEnd(s, i); end > i {
+				m.verbatim(s[i:end])
+				i = end
+				continue
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(s[i:])
+		m.plain(s[i : i+size])
+		i += size
+	}
+}
+
+// link writes the link starting at s[i], encoding its text and keeping its destination, title and reference label,
+// and returns its end. It returns i, writing nothing, if s[i] starts no link.
+func (m *markup) link(s string, i int) int {
+	close := bracketEnd(s, i)
+	if close < 0 {
+		return i
+	}
+	text := s[i+1 : close-1]
+
+	switch {
+	case strings.HasPrefix(text, "^"): // footnote reference
+		m.verbatim(s[i:close])
+		return close
+	case close < len(s) && s[close] == '(':
+		end := parenEnd(s, close)
+		if end < 0 {
+			return i
+		}
+		m.verbatim("[")
+		m.inline(text)
+		m.verbatim(s[close-1 : end])
+		return end
+	case close < len(s) && s[close] == '[':
+		end := bracketEnd(s, close)
+		if end < 0 {
+			return i
+		}
+		if end == close+2 { // collapsed reference, [label][]
+			m.verbatim(s[i:end])
+			return end
+		}
+		m.verbatim("[")
+		m.inline(text)
+		m.verbatim(s[close-1 : end])
+		return end
+	case m.labels[strings.ToLower(text)]: // shortcut reference, [label]
+		m.verbatim(s[i:close])
+		return close
+	}
+	return i
+}
+
+// linkEnd returns the end of the inline or reference link or image whose text starts at s[i], or -1 if there is none.
+func linkEnd(s string, i int) int {
+	close := bracketEnd(s, i)
+	if close < 0 || close >= len(s) {
+		return -1
+	}
+	switch s[close] {
+	case '(':
+		return parenEnd(s, close)
+	case '[':
+		return bracketEnd(s, close)
+	}
+	return -1
+}
+
+// bracketEnd returns the index after the "]" matching the "[" at s[i], or -1 if there is none. Brackets may nest and
+// may be escaped, and a code span's brackets do not count.
+func bracketEnd(s string, i int) int {
+	return matchingEnd(s, i, '[', ']')
+}
+
+// parenEnd returns the index after the ")" matching the "(" at s[i], or -1 if there is none.
+func parenEnd(s string, i int) int {
+	return matchingEnd(s, i, '(', ')')
+}
+
+func matchingEnd(s string, i int, open, close byte) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '`':
+			j = codeSpanEnd(s, j) - 1
+		case '\n':
+			if j+1 < len(s) && s[j+1] == '\n' {
+				return -1
+			}
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return j + 1
+			}
+		}
+	}
+	return -1
+}
+
+// codeSpanEnd returns the end of the code span whose run of backticks starts at s[i], or the end of the run if no
+// run of the same length closes it.
+func codeSpanEnd(s string, i int) int {
+	run := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+	ticks := s[i : i+run]
+	for j := i + run; j < len(s); {
+		k := strings.Index(s[j:], ticks)
+		if k < 0 {
+			break
+		}
+		j += k
+		n := len(s[j:]) - len(strings.TrimLeft(s[j:], "`"))
+		if n == run {
+			return j + n
+		}
+		j += n
+	}
+	return i + run
+}
+
+// urlEnd returns the end of the bare URL starting at s[i], such as "https://example.com/a_b", or i if none starts
+// there. A URL runs to the next whitespace.
+func urlEnd(s string, i int) int {
+	if i > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(s[:i]); unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return i
+		}
+	}
+	for _, scheme := range []string{"https://", "http://", "www.", "mailto:"} {
+		if strings.HasPrefix(s[i:], scheme) {
+			end := strings.IndexFunc(s[i:], func(r rune) bool {
+				return unicode.IsSpace(r) || r == '<'
+			})
+			if end < 0 {
+				return len(s)
+			}
+			return i + end
+		}
+	}
+	return i
+}
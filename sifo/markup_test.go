@@ -0,0 +1,106 @@
+package sifo
+
+import (
+	"testing"
+)
+
+func TestEncodeHTML(t *testing.T) {
+	cipher := WarmHoldCipher()
+	enc := func(s string) string { return EncodeText(s, cipher) }
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"<p>Hello world</p>", "<p>" + enc("Hello world") + "</p>"},
+		{`<a href="https://example.com/about" title="About us">About us</a>`,
+			`<a href="https://example.com/about" title="About us">` + enc("About us") + "</a>"},
+		{`<img alt="a > b" src="x.png"> then`, `<img alt="a > b" src="x.png">` + enc(" then")},
+		{"Fish &amp; chips&#8212;yes &nbsp;", enc("Fish ") + "&amp;" + enc(" chips") + "&#8212;" + enc("yes ") + "&nbsp;"},
+		{"rock & roll", enc("rock & roll")},
+		{"<!-- keep this --><b>bold</b>", "<!-- keep this --><b>" + enc("bold") + "</b>"},
+		{"Run <code>go test</code> now", enc("Run ") + "<code>go test</code>" + enc(" now")},
+		{"<pre>line one\n<b>two</b>\n</pre>\nafter", "<pre>line one\n<b>two</b>\n</pre>" + enc("\nafter")},
+		{`<script>if (a < b) { x = "</p>"; }</script>text`, `<script>if (a < b) { x = "</p>"; }</script>` + enc("text")},
+		{"<div><div>in</div>out</div>", "<div><div>" + enc("in") + "</div>" + enc("out") + "</div>"},
+		{"<!DOCTYPE html><TITLE>Page</TITLE>", "<!DOCTYPE html><TITLE>" + enc("Page") + "</TITLE>"},
+		{"1 < 2 and 3 > 2", enc("1 < 2 and 3 > 2")},
+		{"<p>unclosed <a href='x", "<p>" + enc("unclosed <a href='x")},
+	}
+
+	for _, test := range tests {
+		if result := EncodeHTML(test.input, cipher); result != test.expected {
+			t.Errorf("EncodeHTML(%q) = %q; want %q", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestEncodeMarkdown(t *testing.T) {
+	cipher := WarmHoldCipher()
+	enc := func(s string) string { return EncodeText(s, cipher) }
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"heading and emphasis", "# Title\n\nSome *bold* text.\n", enc("# Title\n\nSome *bold* text.\n")},
+		{"code span", "Call `encode(word)` twice.", enc("Call ") + "`encode(word)`" + enc(" twice.")},
+		{"double code span", "Use ``a ` b`` here", enc("Use ") + "``a ` b``" + enc(" here")},
+		{"inline link", "See [the docs](https://example.com/docs \"Docs\") now.",
+			enc("See ") + "[" + enc("the docs") + "](https://example.com/docs \"Docs\")" + enc(" now.")},
+		{"nested link text", "[**bold** `code`](x)", "[" + enc("**bold** ") + "`code`" + "](x)"},
+		{"image", "An ![alt text](pic.png) here.", enc("An ") + "![alt text](pic.png)" + enc(" here.")},
+		{"reference link", "Read [the guide][guide].\n\n[guide]: https://example.com/guide\n",
+			enc("Read ") + "[" + enc("the guide") + "][guide]" + enc(".\n\n") + "[guide]: https://example.com/guide\n"},
+		{"shortcut reference", "Read [guide] and [other].\n\n[Guide]: /guide\n",
+			enc("Read ") + "[guide]" + enc(" and [other].\n\n") + "[Guide]: /guide\n"},
+		{"footnote", "Claim.[^1]\n\n[^1]: The source.\n",
+			enc("Claim.") + "[^1]" + enc("\n\n") + "[^1]:" + enc(" The source.\n")},
+		{"autolink and bare URL", "Go to <https://a.io/x> or https://b.io/some_path, or www.c.io.",
+			enc("Go to ") + "<https://a.io/x>" + enc(" or ") + "https://b.io/some_path," + enc(" or ") + "www.c.io."},
+		{"word ending in http", "ahttp://x", enc("ahttp://x")},
+		{"escape", `a \*literal\* star`, enc("a ") + `\*` + enc("literal") + `\*` + enc(" star")},
+		{"inline HTML", "Some <em class=\"x\">text</em> &amp; more",
+			enc("Some ") + "<em class=\"x\">" + enc("text") + "</em>" + enc(" ") + "&amp;" + enc(" more")},
+		{"fenced code", "Before\n\n```go\nfmt.Println(\"hi\")\n```\n\nAfter\n",
+			enc("Before\n\n") + "```go\nfmt.Println(\"hi\")\n```\n" + enc("\nAfter\n")},
+		{"tilde fence with a longer close", "~~~\ncode\n~~~~\ntext", "~~~\ncode\n~~~~\n" + enc("text")},
+		{"unclosed fence", "```\ncode\nmore", "```\ncode\nmore"},
+		{"indented code", "Text\n\n    code line\n    more\n\nText\n",
+			enc("Text\n\n") + "    code line\n    more\n\n" + enc("Text\n")},
+		{"indented list continuation", "- item\n\n    more of the item\n", enc("- item\n\n    more of the item\n")},
+		{"front matter", "---\ntitle: Post\n---\nBody\n", "---\ntitle: Post\n---\n" + enc("Body\n")},
+		{"HTML block", "<pre>\nkeep me\n</pre>\nText\n", "<pre>\nkeep me\n</pre>\n" + enc("Text\n")},
+		{"HTML comment block", "<!--\nnote\n-->\nText", "<!--\nnote\n-->\n" + enc("Text")},
+		{"text after an HTML block", "<pre>x</pre> hello world\n", "<pre>x</pre>" + enc(" hello world\n")},
+		{"text after a multi-line HTML block", "<SCRIPT>\nx = 1\n</Script> hello <b>world</b>\nmore\n",
+			"<SCRIPT>\nx = 1\n</Script>" + enc(" hello ") + "<b>" + enc("world") + "</b>" + enc("\nmore\n")},
+		{"text after an HTML comment", "<!-- note --> hello\n", "<!-- note -->" + enc(" hello\n")},
+		{"table", "| Name | Age |\n| --- | --- |\n| Ann | 3 |\n", enc("| Name | Age |\n| --- | --- |\n| Ann | 3 |\n")},
+	}
+
+	for _, test := range tests {
+		if result := EncodeMarkdown(test.input, cipher); result != test.expected {
+			t.Errorf("EncodeMarkdown() of %s = %q; want %q", test.name, result, test.expected)
+		}
+	}
+}
+
+func TestEncodeMarkupRoundTrip(t *testing.T) {
+	document := "# Notes\n\nThe [quick brown fox](https://example.com/fox) jumps over `lazy.Dog()`.\n\n" +
+		"```\nkeep = true\n```\n\n<p class=\"note\">All <b>done</b> &mdash; see www.example.com.</p>\n"
+
+	for _, cipher := range giantCiphers() {
+		inverse, err := Inverse(cipher)
+		if err != nil {
+			t.Fatalf("Inverse() error = %v", err)
+		}
+		if decoded := EncodeMarkdown(EncodeMarkdown(document, cipher), inverse); decoded != document {
+			t.Errorf("EncodeMarkdown() with the inverse = %q; want %q", decoded, document)
+		}
+		if decoded := EncodeHTML(EncodeHTML(document, cipher), inverse); decoded != document {
+			t.Errorf("EncodeHTML() with the inverse = %q; want %q", decoded, document)
+		}
+	}
+}
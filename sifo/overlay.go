@@ -0,0 +1,198 @@
+package sifo
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Tag classifies a word in an overlay so that scoring rules can treat it differently from ordinary words.
+type Tag string
+
+const (
+	TagProperNoun   Tag = "proper noun"
+	TagAbbreviation Tag = "abbreviation"
+	TagArchaic      Tag = "archaic"
+	TagOffensive    Tag = "offensive"
+	TagForeign      Tag = "foreign"
+)
+
+// tags are the tags an overlay may use. Scoring and the guard match tags exactly, so an overlay with any other tag is
+// rejected rather than silently ignored.
+var tags = map[Tag]bool{
+	TagProperNoun:   true,
+	TagAbbreviation: true,
+	TagArchaic:      true,
+	TagOffensive:    true,
+	TagForeign:      true,
+}
+
+// OverlayEntry is what an overlay says about one word.
+type OverlayEntry struct {
+	Exclude bool    // leave the word out of the word list
+	Weight  float64 // multiplies the word's count; 1 leaves it unchanged
+	Tags    []Tag
+}
+
+// Overlay curates a word list without editing it: it excludes words, re-weights their counts and tags them. An
+// overlay file is a CSV of "Word,Weight,Tags" records, reviewed and versioned alongside the word list:
+//
+//	Word,Weight,Tags
+//	# fragments that are not words
+//	ety,exclude,
+//	ole,0,
+//	las,0.5,foreign
+//	paris,,proper noun
+//	dis,,abbreviation|archaic
+//
+// A weight of "exclude" or 0 excludes the word, an empty weight leaves its count unchanged, and tags are separated
+// by "|" and must be one of the Tag constants. Lines starting with "#" are comments.
+type Overlay map[string]OverlayEntry
+
+// OverlayFilename returns the name of the overlay file for a word list, such as words.overlay.csv for words.csv.
+// LoadWords and LoadOrBuildDictionary apply it when it exists.
+func OverlayFilename(wordsFile string) string {
+	base := strings.TrimSuffix(wordsFile, ".gz")
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	return base + ".overlay.csv"
+}
+
+// LoadOverlay reads an overlay from a file.
+func LoadOverlay(filename string) (Overlay, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	overlay, err := ReadOverlay(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return overlay, nil
+}
+
+// loadOverlayFor reads the overlay file of a word list. A word list without one has a nil overlay.
+func loadOverlayFor(wordsFile string) (Overlay, error) {
+	overlay, err := LoadOverlay(OverlayFilename(wordsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return overlay, err
+}
+
+// ReadOverlay reads an overlay. Unlike a word list, an overlay is written by hand to be exact, so a malformed or
+// repeated entry is an error rather than a skipped line.
+func ReadOverlay(r io.Reader) (Overlay, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	overlay := make(Overlay)
+	first := true
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		if first {
+			first = false
+			if strings.EqualFold(strings.TrimSpace(record[0]), "word") {
+				continue
+			}
+		}
+
+		word := strings.TrimSpace(record[0])
+		if word == "" {
+			return nil, fmt.Errorf("line %d: missing word", line)
+		}
+		if _, ok := overlay[word]; ok {
+			return nil, fmt.Errorf("line %d: %q is already in the overlay", line, word)
+		}
+
+		entry := OverlayEntry{Weight: 1}
+		if len(record) > 1 {
+			switch weight := strings.TrimSpace(record[1]); weight {
+			case "":
+			case "exclude":
+				entry.Exclude = true
+			default:
+				w, err := strconv.ParseFloat(weight, 64)
+				if err != nil || w < 0 || math.IsInf(w, 0) || math.IsNaN(w) {
+					return nil, fmt.Errorf("line %d: weight %q is not \"exclude\" or a number 0 or more", line, weight)
+				}
+				entry.Weight = w
+				entry.Exclude = w == 0
+			}
+		}
+		if len(record) > 2 {
+			for _, tag := range strings.Split(record[2], "|") {
+				if tag = strings.TrimSpace(tag); tag == "" {
+					continue
+				}
+				if !tags[Tag(tag)] {
+					return nil, fmt.Errorf("line %d: unknown tag %q", line, tag)
+				}
+				entry.Tags = append(entry.Tags, Tag(tag))
+			}
+		}
+
+		overlay[word] = entry
+	}
+
+	return overlay, nil
+}
+
+// Apply returns the words with the overlay's exclusions and weights applied. words is not modified.
+func (o Overlay) Apply(words map[string]int64) map[string]int64 {
+	curated := make(map[string]int64, len(words))
+	for word, count := range words {
+		entry, ok := o[word]
+		switch {
+		case !ok:
+			curated[word] = count
+		case entry.Exclude:
+		default:
+			curated[word] = int64(math.Round(float64(count) * entry.Weight))
+		}
+	}
+	return curated
+}
+
+// Excludes reports whether the overlay excludes word.
+func (o Overlay) Excludes(word string) bool {
+	return o[word].Exclude
+}
+
+// HasTag reports whether the overlay tags word with tag.
+func (o Overlay) HasTag(word string, tag Tag) bool {
+	for _, t := range o[word].Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// tagWeight returns the product of the weights of word's tags. Tags without a weight count as 1.
+func (o Overlay) tagWeight(word string, weights map[Tag]float64) float64 {
+	w := 1.0
+	for _, tag := range o[word].Tags {
+		if tw, ok := weights[tag]; ok {
+			w *= tw
+		}
+	}
+	return w
+}
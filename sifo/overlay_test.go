@@ -0,0 +1,161 @@
+package sifo
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadOverlay(t *testing.T) {
+	input := `Word,Weight,Tags
+# noise
+ety,exclude,
+ole,0,
+las,0.5,foreign
+paris,,proper noun
+dis,,abbreviation | archaic
+john
+`
+	overlay, err := ReadOverlay(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadOverlay() error = %v", err)
+	}
+
+	want := Overlay{
+		"ety":   {Exclude: true, Weight: 1},
+		"ole":   {Exclude: true, Weight: 0},
+		"las":   {Weight: 0.5, Tags: []Tag{TagForeign}},
+		"paris": {Weight: 1, Tags: []Tag{TagProperNoun}},
+		"dis":   {Weight: 1, Tags: []Tag{TagAbbreviation, TagArchaic}},
+		"john":  {Weight: 1},
+	}
+	if !reflect.DeepEqual(overlay, want) {
+		t.Errorf("ReadOverlay() = %v; want %v", overlay, want)
+	}
+}
+
+func TestReadOverlayErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Word,Weight,Tags\nety,maybe,\n", `line 2: weight "maybe"`},
+		{"Word,Weight,Tags\nety,-1,\n", `line 2: weight "-1"`},
+		{"ety,exclude,\nety,0.5,\n", `line 2: "ety" is already in the overlay`},
+		{"Word,Weight,Tags\n,0.5,\n", "line 2: missing word"},
+		{"Word,Weight,Tags\nbum,,archaic|ofensive\n", `line 2: unknown tag "ofensive"`},
+	}
+
+	for _, test := range tests {
+		_, err := ReadOverlay(strings.NewReader(test.input))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("ReadOverlay(%q) error = %v; want it to contain %q", test.input, err, test.want)
+		}
+	}
+}
+
+func TestOverlayApply(t *testing.T) {
+	overlay := Overlay{
+		"ety": {Exclude: true},
+		"las": {Weight: 0.5, Tags: []Tag{TagForeign}},
+	}
+	words := map[string]int64{"ety": 100, "las": 101, "the": 1000}
+
+	got := overlay.Apply(words)
+	want := map[string]int64{"las": 51, "the": 1000}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() = %v; want %v", got, want)
+	}
+	if len(words) != 3 {
+		t.Errorf("Apply() modified its argument")
+	}
+
+	if !overlay.Excludes("ety") || overlay.Excludes("the") {
+		t.Errorf("Excludes() did not report ety excluded and the included")
+	}
+	if !overlay.HasTag("las", TagForeign) || overlay.HasTag("las", TagArchaic) || overlay.HasTag("the", TagForeign) {
+		t.Errorf("HasTag() did not report las foreign only")
+	}
+}
+
+func TestOverlayFilename(t *testing.T) {
+	tests := map[string]string{
+		"words.csv":      "words.overlay.csv",
+		"lists/kids.txt": "lists/kids.overlay.csv",
+		"words.csv.gz":   "words.overlay.csv",
+	}
+	for wordsFile, want := range tests {
+		if got := OverlayFilename(wordsFile); got != want {
+			t.Errorf("OverlayFilename(%q) = %q; want %q", wordsFile, got, want)
+		}
+	}
+}
+
+func TestLoadWordsOverlay(t *testing.T) {
+	dir := t.TempDir()
+	wordsFile := filepath.Join(dir, "words.csv")
+	writeFile(t, wordsFile, "Word,Count Per Billion\nthe,1000\nety,100\nlas,100\n")
+
+	if words := LoadWords(wordsFile); len(words) != 3 {
+		t.Errorf("LoadWords() without an overlay = %v; want 3 words", words)
+	}
+
+	writeFile(t, OverlayFilename(wordsFile), "Word,Weight,Tags\nety,exclude,\nlas,0.5,foreign\n")
+	want := map[string]int64{"the": 1000, "las": 50}
+	if words := LoadWords(wordsFile); !reflect.DeepEqual(words, want) {
+		t.Errorf("LoadWords() with an overlay = %v; want %v", words, want)
+	}
+}
+
+func TestLoadOrBuildDictionaryOverlay(t *testing.T) {
+	dir := t.TempDir()
+	wordsFile := filepath.Join(dir, "words.csv")
+	cacheFile := filepath.Join(dir, "words.dict")
+	writeFile(t, wordsFile, "Word,Count Per Billion\nthe,1000\nety,100\n")
+
+	if dict, _ := LoadOrBuildDictionary(wordsFile, cacheFile, English); len(dict.Words) != 2 {
+		t.Fatalf("LoadOrBuildDictionary() without an overlay has %d words; want 2", len(dict.Words))
+	}
+
+	// Adding an overlay invalidates the compiled dictionary.
+	writeFile(t, OverlayFilename(wordsFile), "Word,Weight,Tags\nety,exclude,\nthe,,archaic\n")
+	dict, err := LoadOrBuildDictionary(wordsFile, cacheFile, English)
+	if err != nil {
+		t.Fatalf("LoadOrBuildDictionary() error = %v", err)
+	}
+	if _, ok := dict.Words["ety"]; ok {
+		t.Errorf("LoadOrBuildDictionary() kept a word the overlay excludes")
+	}
+	if !dict.Overlay.HasTag("the", TagArchaic) {
+		t.Errorf("LoadOrBuildDictionary() did not set the dictionary's overlay")
+	}
+
+	if cached, _ := LoadOrBuildDictionary(wordsFile, cacheFile, English); !cached.Overlay.HasTag("the", TagArchaic) {
+		t.Errorf("LoadOrBuildDictionary() from the cache did not set the dictionary's overlay")
+	}
+}
+
+func TestScoreOverlay(t *testing.T) {
+	words := map[string]int64{"stop": 1000000, "spot": 1000000, "tops": 1000000}
+	cipher := completeCipher(t, Cipher{"s": "t", "t": "o", "o": "p", "p": "s"}) // stop -> tops
+	dict := NewDictionary(words, English)
+	plain := Score(dict, cipher, false)
+
+	dict.Overlay = Overlay{"tops": {Weight: 1, Tags: []Tag{TagArchaic}}}
+	dict.TagWeights = map[Tag]float64{TagArchaic: 0.5}
+	tagged := Score(dict, cipher, false)
+	if want := plain - 5*occurrenceScore(1000000); tagged < want-1e-9 || tagged > want+1e-9 {
+		t.Errorf("Score() with the encoded word tagged at half weight = %.4f; want %.4f", tagged, want)
+	}
+
+	// Excluding a word scores as if it were not in the word list at all.
+	dict.Overlay = Overlay{"tops": {Exclude: true}}
+	dict.TagWeights = nil
+	without := dict
+	without.Overlay = nil
+	without.Words = map[string]int64{"stop": 1000000, "spot": 1000000}
+	if excluded, want := Score(dict, cipher, false), Score(without, cipher, false); excluded != want {
+		t.Errorf("Score() with the encoded word excluded = %.4f; want %.4f", excluded, want)
+	}
+}
@@ -0,0 +1,176 @@
+package sifo
+
+import (
+	"fmt"
+)
+
+// A cipher that encodes its keys to a permutation of themselves, as every single-letter cipher the search finds does,
+// is an element of a permutation group, and the functions here work with it as one. A letter that is not a key
+// encodes to itself, so a cipher holding only "(b c)(v z)" is the permutation that swaps those letters and leaves the
+// rest alone, and the identity is the empty cipher. Inverse gives a cipher's inverse.
+//
+// Composition applies the right-hand cipher first, so that WarmHold ∘ (b c) encodes "b" as WarmHold encodes "c".
+
+// Cycles returns the cipher's cycles, each starting at its first letter in order and in order of those letters. A
+// key that encodes to itself is a cycle of one. Only a cipher that encodes its keys to a permutation of themselves
+// has cycles.
+func Cycles(cipher Cipher) ([][]string, error) {
+	seen := make(map[string]bool, len(cipher))
+	var all [][]string
+	for _, start := range sortedKeys(cipher) {
+		if seen[start] {
+			continue
+		}
+		var cycle []string
+		for key := start; ; {
+			if seen[key] {
+				return nil, fmt.Errorf("cipher is not a permutation: more than one key encodes to %q", key)
+			}
+			seen[key] = true
+			cycle = append(cycle, key)
+
+			value := cipher[key]
+			if value == start {
+				break
+			}
+			if _, ok := cipher[value]; !ok {
+				return nil, fmt.Errorf("cipher is not a permutation: %q encodes to %q, which is not a key", key, value)
+			}
+			key = value
+		}
+		all = append(all, cycle)
+	}
+	return all, nil
+}
+
+// apply returns what the cipher encodes key to, key itself if it is not a key.
+func apply(cipher Cipher, key string) string {
+	if value, ok := cipher[key]; ok {
+		return value
+	}
+	return key
+}
+
+// Compose returns a ∘ b, the cipher that encodes each letter first with b and then with a. Letters the result
+// encodes to themselves are left out.
+func Compose(a, b Cipher) (Cipher, error) {
+	for _, c := range []Cipher{a, b} {
+		if _, err := Cycles(c); err != nil {
+			return nil, err
+		}
+	}
+
+	composed := make(Cipher, len(a))
+	for _, c := range []Cipher{a, b} {
+		for key := range c {
+			if value := apply(a, apply(b, key)); value != key {
+				composed[key] = value
+			}
+		}
+	}
+	return composed, nil
+}
+
+// Power returns the cipher applied k times. A negative k applies its inverse, and a k of 0 gives the identity.
+// Letters the result encodes to themselves are left out.
+func Power(cipher Cipher, k int) (Cipher, error) {
+	all, err := Cycles(cipher)
+	if err != nil {
+		return nil, err
+	}
+
+	powered := make(Cipher, len(cipher))
+	for _, cycle := range all {
+		n := len(cycle)
+		shift := ((k % n) + n) % n
+		if shift == 0 {
+			continue
+		}
+		for i, key := range cycle {
+			powered[key] = cycle[(i+shift)%n]
+		}
+	}
+	return powered, nil
+}
+
+// Order returns the least number of times the cipher must be applied to give the identity, the least common
+// multiple of its cycle lengths.
+func Order(cipher Cipher) (int, error) {
+	all, err := Cycles(cipher)
+	if err != nil {
+		return 0, err
+	}
+
+	order := 1
+	for _, cycle := range all {
+		order = order / gcd(order, len(cycle)) * len(cycle)
+	}
+	return order, nil
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// Sign returns 1 if the cipher is an even permutation, one made of an even number of swaps, and -1 if it is odd.
+func Sign(cipher Cipher) (int, error) {
+	all, err := Cycles(cipher)
+	if err != nil {
+		return 0, err
+	}
+
+	sign := 1
+	for _, cycle := range all {
+		if len(cycle)%2 == 0 {
+			sign = -sign
+		}
+	}
+	return sign, nil
+}
+
+// Difference returns the cipher d for which b = a ∘ d: the letters to swap before encoding with a to encode as b
+// does. The difference between WarmHold and WormHeld, for example, is a few swaps.
+func Difference(a, b Cipher) (Cipher, error) {
+	inverse, err := Inverse(a)
+	if err != nil {
+		return nil, err
+	}
+	return Compose(inverse, b)
+}
+
+// CayleyDistance returns the least number of swaps of two values that turns cipher a into cipher b.
+func CayleyDistance(a, b Cipher) (int, error) {
+	d, err := Difference(a, b)
+	if err != nil {
+		return 0, err
+	}
+	all, err := Cycles(d)
+	if err != nil {
+		return 0, err
+	}
+
+	distance := 0
+	for _, cycle := range all {
+		distance += len(cycle) - 1
+	}
+	return distance, nil
+}
+
+// HammingDistance returns the number of letters that ciphers a and b encode differently.
+func HammingDistance(a, b Cipher) int {
+	distance := 0
+	for key := range a {
+		if apply(a, key) != apply(b, key) {
+			distance++
+		}
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok && apply(b, key) != key {
+			distance++
+		}
+	}
+	return distance
+}
@@ -0,0 +1,136 @@
+package sifo
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestCycles(t *testing.T) {
+	cipher := Cipher{"a": "b", "b": "c", "c": "a", "d": "e", "e": "d", "f": "f"}
+	want := [][]string{{"a", "b", "c"}, {"d", "e"}, {"f"}}
+	if got, err := Cycles(cipher); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Cycles(%v) = %v, %v; want %v", cipher, got, err, want)
+	}
+
+	if _, err := Cycles(Cipher{"a": "b"}); err == nil {
+		t.Errorf("Cycles() of a cipher that is not a permutation did not fail")
+	}
+}
+
+func TestCompose(t *testing.T) {
+	swap := Cipher{"a": "b", "b": "a"}
+	rotate := Cipher{"a": "b", "b": "c", "c": "a"}
+
+	// The right-hand cipher applies first: rotate ∘ swap sends a to b and then to c, and leaves b where it is.
+	if got, want := mustCompose(t, rotate, swap), (Cipher{"a": "c", "c": "a"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Compose(rotate, swap) = %v; want %v", got, want)
+	}
+	if got, want := mustCompose(t, swap, rotate), (Cipher{"b": "c", "c": "b"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Compose(swap, rotate) = %v; want %v", got, want)
+	}
+
+	for _, cipher := range giantCiphers() {
+		inverse, err := Inverse(cipher)
+		if err != nil {
+			t.Fatalf("Inverse() error = %v", err)
+		}
+		if got := mustCompose(t, cipher, inverse); len(got) != 0 {
+			t.Errorf("Compose(cipher, Inverse(cipher)) = %v; want the identity", got)
+		}
+		if got := mustCompose(t, Cipher{}, cipher); !reflect.DeepEqual(got, cipher) {
+			t.Errorf("Compose(identity, cipher) = %v; want %v", got, cipher)
+		}
+	}
+
+	if _, err := Compose(swap, Cipher{"a": "c"}); err == nil {
+		t.Errorf("Compose() of a cipher that is not a permutation did not fail")
+	}
+}
+
+func mustCompose(t *testing.T, a, b Cipher) Cipher {
+	t.Helper()
+	c, err := Compose(a, b)
+	if err != nil {
+		t.Fatalf("Compose(%v, %v) error = %v", a, b, err)
+	}
+	return c
+}
+
+func TestPowerOrderSign(t *testing.T) {
+	cipher := Cipher{"a": "b", "b": "c", "c": "a", "d": "e", "e": "d"}
+
+	if order, err := Order(cipher); err != nil || order != 6 {
+		t.Errorf("Order(%v) = %d, %v; want 6", cipher, order, err)
+	}
+	if sign, err := Sign(cipher); err != nil || sign != -1 {
+		t.Errorf("Sign(%v) = %d, %v; want -1", cipher, sign, err)
+	}
+
+	square, _ := Power(cipher, 2)
+	if want := mustCompose(t, cipher, cipher); !reflect.DeepEqual(square, want) {
+		t.Errorf("Power(cipher, 2) = %v; want %v", square, want)
+	}
+	if want := (Cipher{"a": "c", "b": "a", "c": "b"}); !reflect.DeepEqual(square, want) {
+		t.Errorf("Power(cipher, 2) = %v; want %v", square, want)
+	}
+
+	for _, cipher := range giantCiphers() {
+		order, err := Order(cipher)
+		if err != nil {
+			t.Fatalf("Order() error = %v", err)
+		}
+		if identity, _ := Power(cipher, order); len(identity) != 0 {
+			t.Errorf("Power(cipher, Order(cipher)) = %v; want the identity", identity)
+		}
+		if once, _ := Power(cipher, order+1); !reflect.DeepEqual(once, cipher) {
+			t.Errorf("Power(cipher, Order(cipher)+1) = %v; want %v", once, cipher)
+		}
+		inverse, _ := Inverse(cipher)
+		if got, _ := Power(cipher, -1); !reflect.DeepEqual(got, inverse) {
+			t.Errorf("Power(cipher, -1) = %v; want %v", got, inverse)
+		}
+
+		// The sign of a composition is the product of the signs.
+		sign, _ := Sign(cipher)
+		composed := mustCompose(t, cipher, WarmHoldCipher())
+		warmHold, _ := Sign(WarmHoldCipher())
+		if got, _ := Sign(composed); got != sign*warmHold {
+			t.Errorf("Sign(Compose(cipher, WarmHold)) = %d; want %d", got, sign*warmHold)
+		}
+	}
+}
+
+func TestDifferenceAndDistance(t *testing.T) {
+	warmHold, wormHeld := WarmHoldCipher(), WormHeldCipher()
+
+	d, err := Difference(warmHold, wormHeld)
+	if err != nil {
+		t.Fatalf("Difference() error = %v", err)
+	}
+	if s, _ := FormatCycles(d); s != "(bvx)" {
+		t.Errorf("Difference(WarmHold, WormHeld) = %s; want (bvx)", s)
+	}
+	if got := mustCompose(t, warmHold, d); !reflect.DeepEqual(got, wormHeld) {
+		t.Errorf("Compose(WarmHold, Difference(WarmHold, WormHeld)) = %v; want WormHeld", got)
+	}
+
+	if distance, err := CayleyDistance(warmHold, wormHeld); err != nil || distance != 2 {
+		t.Errorf("CayleyDistance(WarmHold, WormHeld) = %d, %v; want 2", distance, err)
+	}
+	if distance := HammingDistance(warmHold, wormHeld); distance != 3 {
+		t.Errorf("HammingDistance(WarmHold, WormHeld) = %d; want 3", distance)
+	}
+
+	// One swap of values, as varyCipher makes, is a distance of 1 and changes 2 letters.
+	swapped := varyCipher(warmHold, rand.New(rand.NewSource(1)), 1)
+	if distance, _ := CayleyDistance(warmHold, swapped); distance != 1 {
+		t.Errorf("CayleyDistance() after one swap = %d; want 1", distance)
+	}
+	if distance := HammingDistance(warmHold, swapped); distance != 2 {
+		t.Errorf("HammingDistance() after one swap = %d; want 2", distance)
+	}
+	if distance := HammingDistance(warmHold, warmHold); distance != 0 {
+		t.Errorf("HammingDistance(WarmHold, WarmHold) = %d; want 0", distance)
+	}
+}
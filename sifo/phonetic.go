@@ -0,0 +1,726 @@
+package sifo
+
+import (
+	"strings"
+	"unicode"
+)
+
+// metaphoneKeyLength is the length Double Metaphone keys are truncated to, as in the original algorithm.
+const metaphoneKeyLength = 4
+
+// PhoneticIndex holds the Soundex and Double Metaphone keys of a word list so an encoded word can be checked for
+// sounding like a real word, even when it is spelled like nothing in the dictionary.
+type PhoneticIndex struct {
+	soundex   map[string]bool
+	metaphone map[string]bool
+}
+
+// NewPhoneticIndex precomputes the phonetic keys of the words. Both the primary and alternate Double Metaphone keys
+// are indexed.
+func NewPhoneticIndex(words map[string]int64) *PhoneticIndex {
+	idx := &PhoneticIndex{
+		soundex:   make(map[string]bool),
+		metaphone: make(map[string]bool),
+	}
+
+	for word := range words {
+		if code := Soundex(word); code != "" {
+			idx.soundex[code] = true
+		}
+		primary, alternate := DoubleMetaphone(word)
+		if primary != "" {
+			idx.metaphone[primary] = true
+		}
+		if alternate != "" {
+			idx.metaphone[alternate] = true
+		}
+	}
+
+	return idx
+}
+
+// SoundexMatch reports whether word has the same Soundex code as some indexed word.
+func (idx *PhoneticIndex) SoundexMatch(word string) bool {
+	code := Soundex(word)
+	return code != "" && idx.soundex[code]
+}
+
+// MetaphoneMatch reports whether either Double Metaphone key of word is a key of some indexed word.
+func (idx *PhoneticIndex) MetaphoneMatch(word string) bool {
+	primary, alternate := DoubleMetaphone(word)
+	return (primary != "" && idx.metaphone[primary]) || (alternate != "" && idx.metaphone[alternate])
+}
+
+// Soundex returns the American Soundex code of word: its first letter followed by three digits for the consonant
+// sounds that follow, for example "Robert" and "Rupert" are both "R163". Letters outside a-z are ignored and a word
+// without any letters has no code.
+func Soundex(word string) string {
+	var code strings.Builder
+	var last byte
+
+	for _, char := range strings.ToUpper(word) {
+		if char < 'A' || char > 'Z' {
+			continue
+		}
+		digit := soundexDigit(byte(char))
+		if code.Len() == 0 {
+			code.WriteRune(char)
+			last = digit
+			continue
+		}
+		if char == 'H' || char == 'W' {
+			continue // H and W do not separate consonants with the same code
+		}
+		if digit != '0' && digit != last {
+			code.WriteByte(digit)
+			if code.Len() == 4 {
+				break
+			}
+		}
+		last = digit
+	}
+
+	if code.Len() == 0 {
+		return ""
+	}
+	for code.Len() < 4 {
+		code.WriteByte('0')
+	}
+	return code.String()
+}
+
+func soundexDigit(char byte) byte {
+	switch char {
+	case 'B', 'F', 'P', 'V':
+		return '1'
+	case 'C', 'G', 'J', 'K', 'Q', 'S', 'X', 'Z':
+		return '2'
+	case 'D', 'T':
+		return '3'
+	case 'L':
+		return '4'
+	case 'M', 'N':
+		return '5'
+	case 'R':
+		return '6'
+	}
+	return '0'
+}
+
+// DoubleMetaphone returns the primary and alternate Double Metaphone keys of word, following Lawrence Philips'
+// algorithm. The alternate key captures a second plausible pronunciation, for example "Smith" is "SM0" and "XMT"
+// so that it matches "Schmidt". "0" stands for the "th" sound and "X" for "sh"/"ch". Keys are at most
+// metaphoneKeyLength characters.
+func DoubleMetaphone(word string) (string, string) {
+	m := newMetaphone(word)
+	if len(m.value) == 0 {
+		return "", ""
+	}
+
+	index := 0
+	if m.contains(0, 2, "GN", "KN", "PN", "WR", "PS") {
+		index = 1
+	}
+
+	for !m.complete() && index < len(m.value) {
+		switch m.value[index] {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if index == 0 {
+				m.add("A")
+			}
+			index++
+		case 'B':
+			m.add("P")
+			index = m.skip(index, 'B')
+		case 'Ç':
+			m.add("S")
+			index++
+		case 'C':
+			index = m.handleC(index)
+		case 'D':
+			index = m.handleD(index)
+		case 'F':
+			m.add("F")
+			index = m.skip(index, 'F')
+		case 'G':
+			index = m.handleG(index)
+		case 'H':
+			index = m.handleH(index)
+		case 'J':
+			index = m.handleJ(index)
+		case 'K':
+			m.add("K")
+			index = m.skip(index, 'K')
+		case 'L':
+			index = m.handleL(index)
+		case 'M':
+			m.add("M")
+			if m.conditionM0(index) {
+				index += 2
+			} else {
+				index++
+			}
+		case 'N':
+			m.add("N")
+			index = m.skip(index, 'N')
+		case 'Ñ':
+			m.add("N")
+			index++
+		case 'P':
+			index = m.handleP(index)
+		case 'Q':
+			m.add("K")
+			index = m.skip(index, 'Q')
+		case 'R':
+			index = m.handleR(index)
+		case 'S':
+			index = m.handleS(index)
+		case 'T':
+			index = m.handleT(index)
+		case 'V':
+			m.add("F")
+			index = m.skip(index, 'V')
+		case 'W':
+			index = m.handleW(index)
+		case 'X':
+			index = m.handleX(index)
+		case 'Z':
+			index = m.handleZ(index)
+		default:
+			index++
+		}
+	}
+
+	return m.keys()
+}
+
+// metaphone is the working state of DoubleMetaphone: the uppercased word and the two keys built so far.
+type metaphone struct {
+	value         []rune
+	slavoGermanic bool
+	primary       strings.Builder
+	alternate     strings.Builder
+}
+
+func newMetaphone(word string) *metaphone {
+	var value []rune
+	for _, char := range strings.ToUpper(strings.TrimSpace(word)) {
+		if unicode.IsLetter(char) || char == ' ' {
+			value = append(value, char)
+		}
+	}
+
+	upper := string(value)
+	return &metaphone{
+		value: value,
+		slavoGermanic: strings.ContainsRune(upper, 'W') || strings.ContainsRune(upper, 'K') ||
+			strings.Contains(upper, "CZ") || strings.Contains(upper, "WITZ"),
+	}
+}
+
+func (m *metaphone) keys() (string, string) {
+	primary := m.primary.String()
+	alternate := m.alternate.String()
+	return primary[:min(len(primary), metaphoneKeyLength)], alternate[:min(len(alternate), metaphoneKeyLength)]
+}
+
+func (m *metaphone) complete() bool {
+	return m.primary.Len() >= metaphoneKeyLength && m.alternate.Len() >= metaphoneKeyLength
+}
+
+// add appends to both keys, or to the primary and alternate keys separately when an alternate is given.
+func (m *metaphone) add(primary string, alternate ...string) {
+	m.addPrimary(primary)
+	if len(alternate) > 0 {
+		m.addAlternate(alternate[0])
+	} else {
+		m.addAlternate(primary)
+	}
+}
+
+func (m *metaphone) addPrimary(s string) {
+	m.primary.WriteString(s)
+}
+
+func (m *metaphone) addAlternate(s string) {
+	m.alternate.WriteString(s)
+}
+
+// at returns the letter at index, or 0 when index is out of range.
+func (m *metaphone) at(index int) rune {
+	if index < 0 || index >= len(m.value) {
+		return 0
+	}
+	return m.value[index]
+}
+
+func (m *metaphone) isVowel(index int) bool {
+	return strings.ContainsRune("AEIOUY", m.at(index))
+}
+
+// contains reports whether the length letters starting at start are one of the criteria.
+func (m *metaphone) contains(start, length int, criteria ...string) bool {
+	if start < 0 || start+length > len(m.value) {
+		return false
+	}
+	target := string(m.value[start : start+length])
+	for _, c := range criteria {
+		if target == c {
+			return true
+		}
+	}
+	return false
+}
+
+// skip moves past the letter at index and, when it is doubled, the letter after it.
+func (m *metaphone) skip(index int, double rune) int {
+	if m.at(index+1) == double {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) germanic() bool {
+	return m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH")
+}
+
+func (m *metaphone) handleC(index int) int {
+	switch {
+	case m.conditionC0(index):
+		// various germanic
+		m.add("K")
+		return index + 2
+	case index == 0 && m.contains(index, 6, "CAESAR"):
+		m.add("S")
+		return index + 2
+	case m.contains(index, 2, "CH"):
+		return m.handleCH(index)
+	case m.contains(index, 2, "CZ") && !m.contains(index-2, 4, "WICZ"):
+		// "Czerny"
+		m.add("S", "X")
+		return index + 2
+	case m.contains(index+1, 3, "CIA"):
+		// "focaccia"
+		m.add("X")
+		return index + 3
+	case m.contains(index, 2, "CC") && !(index == 1 && m.at(0) == 'M'):
+		// double "cc" but not "McClelland"
+		return m.handleCC(index)
+	case m.contains(index, 2, "CK", "CG", "CQ"):
+		m.add("K")
+		return index + 2
+	case m.contains(index, 2, "CI", "CE", "CY"):
+		// Italian vs. English
+		if m.contains(index, 3, "CIO", "CIE", "CIA") {
+			m.add("S", "X")
+		} else {
+			m.add("S")
+		}
+		return index + 2
+	}
+
+	m.add("K")
+	switch {
+	case m.contains(index+1, 2, " C", " Q", " G"):
+		// "Mac Caffrey", "Mac Gregor"
+		return index + 3
+	case m.contains(index+1, 1, "C", "K", "Q") && !m.contains(index+1, 2, "CE", "CI"):
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) conditionC0(index int) bool {
+	if m.contains(index, 4, "CHIA") {
+		return true
+	}
+	if index <= 1 || m.isVowel(index-2) || !m.contains(index-1, 3, "ACH") {
+		return false
+	}
+	c := m.at(index + 2)
+	return (c != 'I' && c != 'E') || m.contains(index-2, 6, "BACHER", "MACHER")
+}
+
+func (m *metaphone) handleCC(index int) int {
+	if m.contains(index+2, 1, "I", "E", "H") && !m.contains(index+2, 2, "HU") {
+		// "bellocchio" but not "bacchus"
+		if (index == 1 && m.at(index-1) == 'A') || m.contains(index-1, 5, "UCCEE", "UCCES") {
+			// "accident", "accede", "succeed"
+			m.add("KS")
+		} else {
+			// "bacci", "bertucci", other Italian
+			m.add("X")
+		}
+		return index + 3
+	}
+
+	// Pierce's rule
+	m.add("K")
+	return index + 2
+}
+
+func (m *metaphone) handleCH(index int) int {
+	switch {
+	case index > 0 && m.contains(index, 4, "CHAE"):
+		// "Michael"
+		m.add("K", "X")
+	case m.conditionCH0(index):
+		// Greek roots, "chemistry", "chorus"
+		m.add("K")
+	case m.conditionCH1(index):
+		// Germanic, Greek, or otherwise "ch" for "kh" sound
+		m.add("K")
+	case index > 0 && m.contains(0, 2, "MC"):
+		m.add("K")
+	case index > 0:
+		m.add("X", "K")
+	default:
+		m.add("X")
+	}
+	return index + 2
+}
+
+func (m *metaphone) conditionCH0(index int) bool {
+	if index != 0 {
+		return false
+	}
+	if !m.contains(index+1, 5, "HARAC", "HARIS") && !m.contains(index+1, 3, "HOR", "HYM", "HIA", "HEM") {
+		return false
+	}
+	return !m.contains(0, 5, "CHORE")
+}
+
+func (m *metaphone) conditionCH1(index int) bool {
+	return m.germanic() ||
+		m.contains(index-2, 6, "ORCHES", "ARCHIT", "ORCHID") ||
+		m.contains(index+2, 1, "T", "S") ||
+		((m.contains(index-1, 1, "A", "O", "U", "E") || index == 0) &&
+			(m.contains(index+2, 1, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") || index+1 == len(m.value)-1))
+}
+
+func (m *metaphone) handleD(index int) int {
+	switch {
+	case m.contains(index, 2, "DG"):
+		if m.contains(index+2, 1, "I", "E", "Y") {
+			// "edge"
+			m.add("J")
+			return index + 3
+		}
+		// "Edgar"
+		m.add("TK")
+		return index + 2
+	case m.contains(index, 2, "DT", "DD"):
+		m.add("T")
+		return index + 2
+	}
+	m.add("T")
+	return index + 1
+}
+
+func (m *metaphone) handleG(index int) int {
+	switch {
+	case m.at(index+1) == 'H':
+		return m.handleGH(index)
+	case m.at(index+1) == 'N':
+		switch {
+		case index == 1 && m.isVowel(0) && !m.slavoGermanic:
+			m.add("KN", "N")
+		case !m.contains(index+2, 2, "EY") && m.at(index+1) != 'Y' && !m.slavoGermanic:
+			m.add("N", "KN")
+		default:
+			m.add("KN")
+		}
+		return index + 2
+	case m.contains(index+1, 2, "LI") && !m.slavoGermanic:
+		m.add("KL", "L")
+		return index + 2
+	case index == 0 && (m.at(index+1) == 'Y' ||
+		m.contains(index+1, 2, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		// -ges-, -gep-, -gel-, -gie- at beginning
+		m.add("K", "J")
+		return index + 2
+	case (m.contains(index+1, 2, "ER") || m.at(index+1) == 'Y') &&
+		!m.contains(0, 6, "DANGER", "RANGER", "MANGER") &&
+		!m.contains(index-1, 1, "E", "I") &&
+		!m.contains(index-1, 3, "RGY", "OGY"):
+		// -ger-, -gy-
+		m.add("K", "J")
+		return index + 2
+	case m.contains(index+1, 1, "E", "I", "Y") || m.contains(index-1, 4, "AGGI", "OGGI"):
+		// Italian "biaggi"
+		switch {
+		case m.germanic() || m.contains(index+1, 2, "ET"):
+			// obvious germanic
+			m.add("K")
+		case m.contains(index+1, 3, "IER"):
+			m.add("J")
+		default:
+			m.add("J", "K")
+		}
+		return index + 2
+	case m.at(index+1) == 'G':
+		m.add("K")
+		return index + 2
+	}
+	m.add("K")
+	return index + 1
+}
+
+func (m *metaphone) handleGH(index int) int {
+	switch {
+	case index > 0 && !m.isVowel(index-1):
+		m.add("K")
+	case index == 0:
+		if m.at(index+2) == 'I' {
+			m.add("J")
+		} else {
+			m.add("K")
+		}
+	case (index > 1 && m.contains(index-2, 1, "B", "H", "D")) ||
+		(index > 2 && m.contains(index-3, 1, "B", "H", "D")) ||
+		(index > 3 && m.contains(index-4, 1, "B", "H")):
+		// Parker's rule (with some further refinements), "hugh"
+	case index > 2 && m.at(index-1) == 'U' && m.contains(index-3, 1, "C", "G", "L", "R", "T"):
+		// "laugh", "McLaughlin", "cough", "gough", "rough", "tough"
+		m.add("F")
+	case index > 0 && m.at(index-1) != 'I':
+		m.add("K")
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleH(index int) int {
+	// only keep if first & before vowel or between 2 vowels
+	if (index == 0 || m.isVowel(index-1)) && m.isVowel(index+1) {
+		m.add("H")
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleJ(index int) int {
+	if m.contains(index, 4, "JOSE") || m.contains(0, 4, "SAN ") {
+		// obvious Spanish, "Jose", "San Jacinto"
+		if (index == 0 && (m.at(index+4) == ' ' || len(m.value) == 4)) || m.contains(0, 4, "SAN ") {
+			m.add("H")
+		} else {
+			m.add("J", "H")
+		}
+		return index + 1
+	}
+
+	switch {
+	case index == 0:
+		m.add("J", "A")
+	case m.isVowel(index-1) && !m.slavoGermanic && (m.at(index+1) == 'A' || m.at(index+1) == 'O'):
+		// Spanish pronunciation of "bajador"
+		m.add("J", "H")
+	case index == len(m.value)-1:
+		m.add("J", "")
+	case !m.contains(index+1, 1, "L", "T", "K", "S", "N", "M", "B", "Z") && !m.contains(index-1, 1, "S", "K", "L"):
+		m.add("J")
+	}
+	return m.skip(index, 'J')
+}
+
+func (m *metaphone) handleL(index int) int {
+	if m.at(index+1) != 'L' {
+		m.add("L")
+		return index + 1
+	}
+
+	if m.conditionL0(index) {
+		// Spanish "cabrillo", "gallegos"
+		m.addPrimary("L")
+	} else {
+		m.add("L")
+	}
+	return index + 2
+}
+
+func (m *metaphone) conditionL0(index int) bool {
+	last := len(m.value) - 1
+	if index == len(m.value)-3 && m.contains(index-1, 4, "ILLO", "ILLA", "ALLE") {
+		return true
+	}
+	return (m.contains(last-1, 2, "AS", "OS") || m.contains(last, 1, "A", "O")) && m.contains(index-1, 4, "ALLE")
+}
+
+func (m *metaphone) conditionM0(index int) bool {
+	if m.at(index+1) == 'M' {
+		return true
+	}
+	// "dumb", "thumb"
+	return m.contains(index-1, 3, "UMB") && (index+1 == len(m.value)-1 || m.contains(index+2, 2, "ER"))
+}
+
+func (m *metaphone) handleP(index int) int {
+	if m.at(index+1) == 'H' {
+		m.add("F")
+		return index + 2
+	}
+	m.add("P")
+	if m.contains(index+1, 1, "P", "B") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleR(index int) int {
+	// French "rogier", but exclude "hochmeier"
+	if index == len(m.value)-1 && !m.slavoGermanic && m.contains(index-2, 2, "IE") && !m.contains(index-4, 2, "ME", "MA") {
+		m.addAlternate("R")
+	} else {
+		m.add("R")
+	}
+	return m.skip(index, 'R')
+}
+
+func (m *metaphone) handleS(index int) int {
+	switch {
+	case m.contains(index-1, 3, "ISL", "YSL"):
+		// "island", "isle", "carlisle", "carlysle"
+		return index + 1
+	case index == 0 && m.contains(index, 5, "SUGAR"):
+		m.add("X", "S")
+		return index + 1
+	case m.contains(index, 2, "SH"):
+		if m.contains(index+1, 4, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			// germanic
+			m.add("S")
+		} else {
+			m.add("X")
+		}
+		return index + 2
+	case m.contains(index, 3, "SIO", "SIA") || m.contains(index, 4, "SIAN"):
+		// Italian and Armenian
+		if m.slavoGermanic {
+			m.add("S")
+		} else {
+			m.add("S", "X")
+		}
+		return index + 3
+	case (index == 0 && m.contains(index+1, 1, "M", "N", "L", "W")) || m.contains(index+1, 1, "Z"):
+		// German and anglicisations, "smith" matches "schmidt" and "snider" matches "schneider"
+		m.add("S", "X")
+		return m.skip(index, 'Z')
+	case m.contains(index, 2, "SC"):
+		return m.handleSC(index)
+	}
+
+	if index == len(m.value)-1 && m.contains(index-2, 2, "AI", "OI") {
+		// French "resnais", "artois"
+		m.addAlternate("S")
+	} else {
+		m.add("S")
+	}
+	if m.contains(index+1, 1, "S", "Z") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleSC(index int) int {
+	switch {
+	case m.at(index+2) == 'H':
+		// Schlesinger's rule
+		switch {
+		case m.contains(index+3, 2, "ER", "EN"):
+			// "schermerhorn", "schenker"
+			m.add("X", "SK")
+		case m.contains(index+3, 2, "OO", "UY", "ED", "EM"):
+			// Dutch origin, "school", "schooner"
+			m.add("SK")
+		case index == 0 && !m.isVowel(3) && m.at(3) != 'W':
+			m.add("X", "S")
+		default:
+			m.add("X")
+		}
+	case m.contains(index+2, 1, "I", "E", "Y"):
+		m.add("S")
+	default:
+		m.add("SK")
+	}
+	return index + 3
+}
+
+func (m *metaphone) handleT(index int) int {
+	switch {
+	case m.contains(index, 4, "TION"), m.contains(index, 3, "TIA", "TCH"):
+		m.add("X")
+		return index + 3
+	case m.contains(index, 2, "TH"), m.contains(index, 3, "TTH"):
+		if m.contains(index+2, 2, "OM", "AM") || m.germanic() {
+			// "thomas", "thames" or germanic
+			m.add("T")
+		} else {
+			m.add("0", "T")
+		}
+		return index + 2
+	}
+	m.add("T")
+	if m.contains(index+1, 1, "T", "D") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleW(index int) int {
+	switch {
+	case m.contains(index, 2, "WR"):
+		// can also be in the middle of a word
+		m.add("R")
+		return index + 2
+	case index == 0 && (m.isVowel(index+1) || m.contains(index, 2, "WH")):
+		if m.isVowel(index + 1) {
+			// "Wasserman" should match "Vasserman"
+			m.add("A", "F")
+		} else {
+			// "Uomo" should match "Womo"
+			m.add("A")
+		}
+		return index + 1
+	case (index == len(m.value)-1 && m.isVowel(index-1)) ||
+		m.contains(index-1, 5, "EWSKI", "EWSKY", "OWSKI", "OWSKY") ||
+		m.contains(0, 3, "SCH"):
+		// "Arnow" should match "Arnoff"
+		m.addAlternate("F")
+		return index + 1
+	case m.contains(index, 4, "WICZ", "WITZ"):
+		// Polish "filipowicz"
+		m.add("TS", "FX")
+		return index + 4
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleX(index int) int {
+	if index == 0 {
+		m.add("S")
+		return index + 1
+	}
+
+	// French "breaux"
+	if !(index == len(m.value)-1 && (m.contains(index-3, 3, "IAU", "EAU") || m.contains(index-2, 2, "AU", "OU"))) {
+		m.add("KS")
+	}
+	if m.contains(index+1, 1, "C", "X") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleZ(index int) int {
+	if m.at(index+1) == 'H' {
+		// Chinese pinyin "zhao"
+		m.add("J")
+		return index + 2
+	}
+
+	if m.contains(index+1, 2, "ZO", "ZI", "ZA") || (m.slavoGermanic && index > 0 && m.at(index-1) != 'T') {
+		m.add("S", "TS")
+	} else {
+		m.add("S")
+	}
+	return m.skip(index, 'Z')
+}
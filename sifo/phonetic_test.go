@@ -0,0 +1,112 @@
+package sifo
+
+import (
+	"testing"
+)
+
+func TestSoundex(t *testing.T) {
+	tests := []struct {
+		word     string
+		expected string
+	}{
+		{"Robert", "R163"},   // Standard example
+		{"Rupert", "R163"},   // Sounds like "Robert"
+		{"Rubin", "R150"},    // Padded with zeros
+		{"Ashcraft", "A261"}, // "s" and "c" are separated by "h", so they collapse
+		{"Tymczak", "T522"},  // Vowel separates the two "k" sounds
+		{"Pfister", "P236"},  // "f" has the same code as the first letter
+		{"Honeyman", "H555"}, // Vowels separate repeated "n" and "m"
+		{"warm", "W650"},     // Lowercase input
+		{"a", "A000"},        // Single letter
+		{"", ""},             // Empty string
+		{"123", ""},          // No letters
+	}
+
+	for _, test := range tests {
+		result := Soundex(test.word)
+		if result != test.expected {
+			t.Errorf("Soundex(%q) = %q; want %q", test.word, result, test.expected)
+		}
+	}
+}
+
+func TestDoubleMetaphone(t *testing.T) {
+	tests := []struct {
+		word      string
+		primary   string
+		alternate string
+	}{
+		{"Smith", "SM0", "XMT"},   // "th" and Germanic alternate
+		{"Schmidt", "XMT", "SMT"}, // Matches "Smith" on the alternate
+		{"Thumb", "0M", "TM"},     // Silent "b"
+		{"knight", "NT", "NT"},    // Silent start and silent "gh"
+		{"laugh", "LF", "LF"},     // "gh" as "f"
+		{"cough", "KF", "KF"},     // "gh" as "f"
+		{"Xavier", "SF", "SFR"},   // Initial "x" and French ending
+		{"Arnow", "ARN", "ARNF"},  // Final "w"
+		{"Jose", "HS", "HS"},      // Spanish "j"
+		{"Michael", "MKL", "MXL"}, // "chae"
+		{"chemistry", "KMST", "KMST"},
+		{"edge", "AJ", "AJ"},
+		{"phone", "FN", "FN"},
+		{"", "", ""},
+	}
+
+	for _, test := range tests {
+		primary, alternate := DoubleMetaphone(test.word)
+		if primary != test.primary || alternate != test.alternate {
+			t.Errorf("DoubleMetaphone(%q) = %q, %q; want %q, %q", test.word, primary, alternate, test.primary, test.alternate)
+		}
+	}
+}
+
+func TestPhoneticIndex(t *testing.T) {
+	words := map[string]int64{
+		"night": 1,
+		"phone": 1,
+		"smith": 1,
+	}
+
+	idx := NewPhoneticIndex(words)
+
+	tests := []struct {
+		word      string
+		soundex   bool
+		metaphone bool
+	}{
+		{"nite", false, true},   // Sounds like "night", but Soundex codes the silent "gh"
+		{"fone", false, true},   // Soundex keeps the first letter
+		{"schmidt", true, true}, // Same Soundex as "smith" and matches it on the alternate key
+		{"smyth", true, true},   // Sounds like "smith"
+		{"zzz", false, false},   // Sounds like nothing
+	}
+
+	for _, test := range tests {
+		if result := idx.SoundexMatch(test.word); result != test.soundex {
+			t.Errorf("SoundexMatch(%q) = %v; want %v", test.word, result, test.soundex)
+		}
+		if result := idx.MetaphoneMatch(test.word); result != test.metaphone {
+			t.Errorf("MetaphoneMatch(%q) = %v; want %v", test.word, result, test.metaphone)
+		}
+	}
+}
+
+func TestPhoneticScore(t *testing.T) {
+	idx := NewPhoneticIndex(map[string]int64{"smith": 1})
+
+	tests := []struct {
+		word     string
+		expected float64
+	}{
+		{"smyth", soundexWeight + metaphoneWeight}, // Both keys match
+		{"zmith", metaphoneWeight},                 // Soundex keeps the first letter
+		{"qqq", 0},                                 // Sounds like nothing
+	}
+
+	for _, test := range tests {
+		result := phoneticScore(test.word, idx)
+		if result != test.expected {
+			t.Errorf("phoneticScore(%q) = %v; want %v", test.word, result, test.expected)
+		}
+	}
+}
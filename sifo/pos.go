@@ -0,0 +1,86 @@
+package sifo
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// posWeight is the share of a word's occurrence score added when it encodes to a real word that shares one of its
+// parts of speech, so that encoded sentences keep their grammatical shape.
+const posWeight = 3.0
+
+// PartsOfSpeech maps words to their part-of-speech tags, such as "noun", "verb" or "adj". A word can have several.
+type PartsOfSpeech map[string][]string
+
+// LoadPartsOfSpeech reads a part-of-speech lexicon from a CSV file.
+func LoadPartsOfSpeech(filename string) (PartsOfSpeech, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadPartsOfSpeech(file)
+}
+
+// ReadPartsOfSpeech reads a part-of-speech lexicon. Each line has the word first and its tags in the last column,
+// either on their own ("warm,adj") or after a count as in words.csv ("warm,77220,adj"). Several tags can be given in
+// one column separated by "|" ("hold,verb|noun") or on separate lines. A first line starting with "Word" is taken as a
+// header, and lines without a tag column are skipped.
+func ReadPartsOfSpeech(r io.Reader) (PartsOfSpeech, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	pos := make(PartsOfSpeech)
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 || first && strings.EqualFold(record[0], "word") {
+			continue
+		}
+		if _, err := strconv.ParseInt(record[len(record)-1], 10, 64); err == nil {
+			continue // count column, no tags
+		}
+
+		word := strings.ToLower(strings.TrimSpace(record[0]))
+		for _, tag := range strings.Split(record[len(record)-1], "|") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag != "" && !pos.Is(word, tag) {
+				pos[word] = append(pos[word], tag)
+			}
+		}
+	}
+
+	return pos, nil
+}
+
+// Is reports whether word is tagged with tag.
+func (pos PartsOfSpeech) Is(word, tag string) bool {
+	for _, t := range pos[word] {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Compatible reports whether a and b share a part of speech, so that one can stand in for the other in a sentence.
+// Words without tags are not compatible with anything.
+func (pos PartsOfSpeech) Compatible(a, b string) bool {
+	for _, tag := range pos[a] {
+		if pos.Is(b, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package sifo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadPartsOfSpeech(t *testing.T) {
+	input := `Word,POS
+warm,adj
+warm,verb
+hold,verb|noun
+lonely, adj
+remark,12345,noun
+the,56271872
+word,noun
+`
+
+	pos, err := ReadPartsOfSpeech(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadPartsOfSpeech() error = %v", err)
+	}
+
+	expected := PartsOfSpeech{
+		"warm":   {"adj", "verb"},
+		"hold":   {"verb", "noun"},
+		"lonely": {"adj"},
+		"remark": {"noun"},
+		"word":   {"noun"},
+	}
+	if !reflect.DeepEqual(pos, expected) {
+		t.Errorf("ReadPartsOfSpeech() = %v; want %v", pos, expected)
+	}
+}
+
+func TestPartsOfSpeechCompatible(t *testing.T) {
+	pos := PartsOfSpeech{
+		"warm":   {"adj", "verb"},
+		"hold":   {"verb", "noun"},
+		"lonely": {"adj"},
+		"remark": {"noun", "verb"},
+	}
+
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"warm", "hold", true},      // Both verbs
+		{"lonely", "remark", false}, // Adjective and noun
+		{"lonely", "warm", true},    // Both adjectives
+		{"lonely", "lots", false},   // Untagged word
+		{"lots", "rest", false},     // Neither tagged
+	}
+
+	for _, test := range tests {
+		result := pos.Compatible(test.a, test.b)
+		if result != test.expected {
+			t.Errorf("Compatible(%q, %q) = %v; want %v", test.a, test.b, result, test.expected)
+		}
+	}
+}
+
+func TestScorePartsOfSpeech(t *testing.T) {
+	dict := Dictionary{
+		Words: map[string]int64{
+			"warm": 77220,
+			"hold": 186609,
+		},
+	}
+
+	without := Score(dict, WarmHoldCipher(), false)
+
+	dict.PartsOfSpeech = PartsOfSpeech{
+		"warm": {"verb"},
+		"hold": {"verb"},
+	}
+	with := Score(dict, WarmHoldCipher(), false)
+
+	if with-without != posWeight*occurrenceScore(77220) {
+		t.Errorf("Score() with parts of speech = %.4f; want %.4f more than %.4f", with, posWeight*occurrenceScore(77220), without)
+	}
+}
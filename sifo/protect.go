@@ -0,0 +1,246 @@
+package sifo
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Protection keeps names, ticket IDs and other tokens readable in encoded text: Encode, EncodeText and Decode pass
+// them through unchanged and encode everything around them as usual. A token may have punctuation attached, as in
+// "Acme," or "(JIRA-123)"; only the punctuation is encoded, and punctuation encodes to itself.
+type Protection struct {
+	// Terms are kept wherever they appear as whole words, matching case. A term may hold spaces, as "Acme Corp".
+	Terms []string
+
+	// Patterns are matched against the whole text and against each token with the punctuation around it removed, so
+	// that `^[A-Z]+-\d+$` keeps the ID in "see (JIRA-123)". Whatever they match is kept.
+	Patterns []*regexp.Regexp
+
+	// ProperNouns also keeps capitalized words of more than one letter in the middle of a sentence, such as "Bob" in
+	// "Ask Bob", taking them to be names. Words starting a sentence or a line are encoded.
+	ProperNouns bool
+
+	Language Language // case rules for encoding; zero value is English
+}
+
+// token is a run of text between whitespace, and the part of it left after removing the punctuation around it.
+type token struct {
+	start, end         int // the token in the text
+	coreStart, coreEnd int // the token without leading and trailing punctuation; empty if it has no letters or digits
+}
+
+// tokens splits text into tokens at whitespace.
+func tokens(text string) []token {
+	var all []token
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+
+		t := token{start: i}
+		for i < len(text) {
+			r, size := utf8.DecodeRuneInString(text[i:])
+			if unicode.IsSpace(r) {
+				break
+			}
+			i += size
+		}
+		t.end = i
+
+		core := strings.TrimFunc(text[t.start:t.end], func(r rune) bool {
+			return !isWordRune(r)
+		})
+		if core != "" {
+			t.coreStart = t.start + strings.Index(text[t.start:t.end], core)
+			t.coreEnd = t.coreStart + len(core)
+		} else {
+			t.coreStart, t.coreEnd = t.end, t.end
+		}
+		all = append(all, t)
+	}
+	return all
+}
+
+// spans returns the byte ranges of text to keep, in order and not overlapping.
+func (p *Protection) spans(text string) [][2]int {
+	var spans [][2]int
+
+	for _, term := range p.Terms {
+		if term == "" {
+			continue
+		}
+		for i := 0; ; {
+			j := strings.Index(text[i:], term)
+			if j < 0 {
+				break
+			}
+			start, end := i+j, i+j+len(term)
+			if !wordRuneBefore(text, start) && !wordRuneAfter(text, end) {
+				spans = append(spans, [2]int{start, end})
+			}
+			_, size := utf8.DecodeRuneInString(text[start:])
+			i = start + size
+		}
+	}
+
+	for _, pattern := range p.Patterns {
+		for _, match := range pattern.FindAllStringIndex(text, -1) {
+			if match[1] > match[0] {
+				spans = append(spans, [2]int{match[0], match[1]})
+			}
+		}
+	}
+
+	sentenceStart := true
+	for _, t := range tokens(text) {
+		if t.coreStart == t.coreEnd {
+			continue // dashes, bullets and the like neither start nor end a sentence
+		}
+		core := text[t.coreStart:t.coreEnd]
+		for _, pattern := range p.Patterns {
+			for _, match := range pattern.FindAllStringIndex(core, -1) {
+				if match[1] > match[0] {
+					spans = append(spans, [2]int{t.coreStart + match[0], t.coreStart + match[1]})
+				}
+			}
+		}
+
+		if p.ProperNouns {
+			// A word with only a list marker or quote before it on its line, as in "- Thanks", starts a sentence.
+			line := text[strings.LastIndexByte(text[:t.start], '\n')+1 : t.start]
+			if strings.IndexFunc(line, isWordRune) < 0 {
+				sentenceStart = true
+			}
+			first, _ := utf8.DecodeRuneInString(core)
+			if !sentenceStart && unicode.IsUpper(first) && utf8.RuneCountInString(core) > 1 {
+				spans = append(spans, [2]int{t.coreStart, t.coreEnd})
+			}
+			sentenceStart = endsSentence(text[t.start:t.end])
+		}
+	}
+
+	return mergeSpans(spans)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// wordRuneBefore reports whether the rune before text[i] is a letter or digit, so that a match at i starts inside
+// a word.
+func wordRuneBefore(text string, i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(text[:i])
+	return i > 0 && isWordRune(r)
+}
+
+// wordRuneAfter reports whether the rune at text[i] is a letter or digit, so that a match ending at i ends inside a
+// word.
+func wordRuneAfter(text string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return i < len(text) && isWordRune(r)
+}
+
+// endsSentence reports whether a token ends a sentence, as "done." or "(really?)" do.
+func endsSentence(token string) bool {
+	token = strings.TrimRightFunc(token, func(r rune) bool {
+		return strings.ContainsRune(`)]}"'’”»`, r)
+	})
+	return strings.HasSuffix(token, ".") || strings.HasSuffix(token, "!") || strings.HasSuffix(token, "?")
+}
+
+// mergeSpans sorts spans and joins those that overlap or touch.
+func mergeSpans(spans [][2]int) [][2]int {
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i][0] < spans[j][0]
+	})
+
+	var merged [][2]int
+	for _, span := range spans {
+		if n := len(merged); n > 0 && span[0] <= merged[n-1][1] {
+			if span[1] > merged[n-1][1] {
+				merged[n-1][1] = span[1]
+			}
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// Encode encodes input like the package-level Encode, one word at a time with single spaces between them, and keeps
+// the protected tokens in it. It returns the validation error instead if the cipher is not valid over the alphabet
+// of the protection's language (see Validate).
+func (p *Protection) Encode(input string, cipher Cipher) (string, error) {
+	lang := p.Language.orDefault()
+	if err := lang.check(cipher); err != nil {
+		return "", err
+	}
+	spans := p.spans(input)
+	var encoded strings.Builder
+	for _, t := range tokens(input) {
+		for i := t.start; i < t.end; {
+			for len(spans) > 0 && spans[0][1] <= i {
+				spans = spans[1:]
+			}
+			if len(spans) > 0 && spans[0][0] <= i {
+				end := spans[0][1]
+				if end > t.end {
+					end = t.end
+				}
+				encoded.WriteString(input[i:end])
+				i = end
+				continue
+			}
+
+			end := t.end
+			if len(spans) > 0 && spans[0][0] < end {
+				end = spans[0][0]
+			}
+			encoded.WriteString(lang.encodeWord(input[i:end], cipher))
+			i = end
+		}
+		encoded.WriteRune(' ')
+	}
+	return encoded.String(), nil
+}
+
+// EncodeText encodes text like the package-level EncodeText and keeps the protected tokens in it. Like Encode, it
+// returns the validation error instead if the cipher is not valid.
+func (p *Protection) EncodeText(text string, cipher Cipher) (string, error) {
+	if err := p.Language.check(cipher); err != nil {
+		return "", err
+	}
+	return p.encodeText(text, cipher), nil
+}
+
+// encodeText encodes text with any cipher, keeping the protected tokens.
+func (p *Protection) encodeText(text string, cipher Cipher) string {
+	lang := p.Language.orDefault()
+
+	var encoded strings.Builder
+	i := 0
+	for _, span := range p.spans(text) {
+		encoded.WriteString(lang.encodeRuns(text[i:span[0]], cipher))
+		encoded.WriteString(text[span[0]:span[1]])
+		i = span[1]
+	}
+	encoded.WriteString(lang.encodeRuns(text[i:], cipher))
+	return encoded.String()
+}
+
+// Decode reverses EncodeText. It finds the protected tokens in the encoded text, which are the ones EncodeText kept
+// unless the encoding of some other word happens to be a term or match a pattern: with the term "Hold", WarmHold
+// encodes "Warm" to "Hold", which Decode then keeps rather than decoding back to "Warm". Encoding keeps the case of
+// each letter, so capitalized words are found where they were.
+func (p *Protection) Decode(text string, cipher Cipher) (string, error) {
+	inverse, err := Inverse(cipher)
+	if err != nil {
+		return "", err
+	}
+	return p.encodeText(text, inverse), nil
+}
@@ -0,0 +1,118 @@
+package sifo
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestProtectionEncodeText(t *testing.T) {
+	cipher := WarmHoldCipher()
+	enc := func(s string) string { return EncodeText(s, cipher) }
+	ticket := regexp.MustCompile(`^[A-Z]+-\d+$`)
+	email := regexp.MustCompile(`[\w.+-]+@[\w-]+(\.[\w-]+)+`)
+
+	tests := []struct {
+		name       string
+		protection Protection
+		input      string
+		expected   string
+	}{
+		{"none", Protection{}, "Ask Acme today.", enc("Ask Acme today.")},
+		{"term with punctuation", Protection{Terms: []string{"Acme"}}, "Thanks, Acme, for (Acme)!",
+			enc("Thanks, ") + "Acme" + enc(", for (") + "Acme" + enc(")!")},
+		{"term inside a word", Protection{Terms: []string{"Acme"}}, "Acmes and NotAcme", enc("Acmes and NotAcme")},
+		{"term case", Protection{Terms: []string{"Acme"}}, "ACME acme", enc("ACME acme")},
+		{"term with spaces", Protection{Terms: []string{"Acme Corp"}}, "at Acme Corp.", enc("at ") + "Acme Corp" + enc(".")},
+		{"possessive", Protection{Terms: []string{"Acme"}}, "Acme's plan", "Acme" + enc("'s plan")},
+		{"anchored pattern", Protection{Patterns: []*regexp.Regexp{ticket}}, "Fix (JIRA-123) and ABC-9.",
+			enc("Fix (") + "JIRA-123" + enc(") and ") + "ABC-9" + enc(".")},
+		{"pattern across text", Protection{Patterns: []*regexp.Regexp{email}}, "Mail <bob.smith@example.com>, please",
+			enc("Mail <") + "bob.smith@example.com" + enc(">, please")},
+		{"proper nouns", Protection{ProperNouns: true}, "Please ask Bob and Mary-Jane O'Neil. They know.",
+			enc("Please ask ") + "Bob" + enc(" and ") + "Mary-Jane" + enc(" ") + "O'Neil" + enc(". They know.")},
+		{"sentence starts", Protection{ProperNouns: true}, "Done! Thanks, I said \"Great.\" Next time?\n- Cheers from Paris",
+			enc("Done! Thanks, I said \"") + "Great" + enc(".\" Next time?\n- Cheers from ") + "Paris"},
+		{"all caps", Protection{ProperNouns: true}, "we use NASA data", enc("we use ") + "NASA" + enc(" data")},
+		{"combined", Protection{Terms: []string{"acme"}, Patterns: []*regexp.Regexp{ticket}, ProperNouns: true},
+			"Tell Ann that acme fixed PROJ-7.", enc("Tell ") + "Ann" + enc(" that ") + "acme" + enc(" fixed ") + "PROJ-7" + enc(".")},
+	}
+
+	for _, test := range tests {
+		result, err := test.protection.EncodeText(test.input, cipher)
+		if err != nil || result != test.expected {
+			t.Errorf("EncodeText() with %s = %q, %v; want %q", test.name, result, err, test.expected)
+		}
+	}
+}
+
+func TestProtectionEncode(t *testing.T) {
+	cipher := WarmHoldCipher()
+	p := Protection{Terms: []string{"Acme Corp"}, Patterns: []*regexp.Regexp{regexp.MustCompile(`^[A-Z]+-\d+$`)}}
+
+	input := "Ask  Acme Corp,\tabout (JIRA-123)."
+	expected := encodeWord("Ask", cipher) + " Acme Corp, " + encodeWord("about", cipher) + " (JIRA-123). "
+	if result, err := p.Encode(input, cipher); err != nil || result != expected {
+		t.Errorf("Encode(%q) = %q, %v; want %q", input, result, err, expected)
+	}
+	if result, _ := (&Protection{}).Encode(input, cipher); result != Encode(input, cipher) {
+		t.Errorf("Encode() without protection = %q; want %q", result, Encode(input, cipher))
+	}
+}
+
+func TestProtectionValidates(t *testing.T) {
+	p := Protection{Terms: []string{"Acme"}}
+	bad := WarmHoldCipher()
+	bad["a"] = "a"
+
+	var validation *ValidationError
+	if _, err := p.Encode("Ask Acme", bad); !errors.As(err, &validation) {
+		t.Errorf("Encode() with an invalid cipher error = %v; want a *ValidationError", err)
+	}
+	if _, err := p.EncodeText("Ask Acme", bad); !errors.As(err, &validation) {
+		t.Errorf("EncodeText() with an invalid cipher error = %v; want a *ValidationError", err)
+	}
+}
+
+func TestProtectionDecode(t *testing.T) {
+	p := Protection{
+		Terms:       []string{"Acme"},
+		Patterns:    []*regexp.Regexp{regexp.MustCompile(`^[A-Z]+-\d+$`)},
+		ProperNouns: true,
+	}
+	text := "Hi team,\nAcme needs JIRA-42 fixed by Friday, says Dana. Thanks!\n"
+
+	for _, cipher := range giantCiphers() {
+		encoded, err := p.EncodeText(text, cipher)
+		if err != nil {
+			t.Fatalf("EncodeText() error = %v", err)
+		}
+		decoded, err := p.Decode(encoded, cipher)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if decoded != text {
+			t.Errorf("Decode(%q) = %q; want %q", encoded, decoded, text)
+		}
+	}
+}
+
+func TestProtectionDecodeCollision(t *testing.T) {
+	// WarmHold encodes "Warm" to the protected term "Hold", so Decode cannot tell it from the kept one.
+	p := Protection{Terms: []string{"Hold"}}
+	cipher := WarmHoldCipher()
+
+	encoded, err := p.EncodeText("Warm Hold", cipher)
+	if err != nil || encoded != "Hold Hold" {
+		t.Fatalf("EncodeText() = %q, %v; want %q", encoded, err, "Hold Hold")
+	}
+	if decoded, err := p.Decode(encoded, cipher); err != nil || decoded != "Hold Hold" {
+		t.Errorf("Decode(%q) = %q, %v; want the colliding encoding kept as %q", encoded, decoded, err, "Hold Hold")
+	}
+
+	// Without the collision, the same protection decodes exactly.
+	encoded, _ = p.EncodeText("Lots Hold", cipher)
+	if decoded, _ := p.Decode(encoded, cipher); decoded != "Lots Hold" {
+		t.Errorf("Decode(EncodeText(%q)) = %q", "Lots Hold", decoded)
+	}
+}
@@ -0,0 +1,229 @@
+package sifo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A cipher can be written down in four ways, each of which reads back to the same cipher:
+//
+//   - a key string, the value of each letter of the alphabet in order, such as "owbnagpyuxvrdmefjltsichzkq" for
+//     WarmHold;
+//   - JSON, an object from keys to values;
+//   - a Markdown "Raw | Enc" table, as in the README;
+//   - cycle notation, such as "(aoe)(bwhy)..." for a cipher that sends a to o, o to e and e back to a.
+//
+// Parsing checks only the form. Validate checks the cipher itself.
+
+// FormatKey returns the cipher as a key string over the English alphabet.
+func FormatKey(cipher Cipher) (string, error) {
+	return English.FormatKey(cipher)
+}
+
+// FormatKey returns the cipher as a key string over the language's alphabet. Only a cipher with a one-letter value
+// for every letter of the alphabet, and no other keys, has one.
+func (l Language) FormatKey(cipher Cipher) (string, error) {
+	if len(cipher) != len(l.Alphabet) {
+		return "", fmt.Errorf("a key string needs exactly the %d letters of the alphabet as keys; the cipher has %d keys", len(l.Alphabet), len(cipher))
+	}
+
+	var key strings.Builder
+	for _, letter := range l.Letters() {
+		value, ok := cipher[letter]
+		if !ok {
+			return "", fmt.Errorf("a key string needs a key for every letter of the alphabet; %q is missing", letter)
+		}
+		if utf8.RuneCountInString(value) != 1 {
+			return "", fmt.Errorf("a key string needs one-letter values; %q encodes to %q", letter, value)
+		}
+		key.WriteString(value)
+	}
+	return key.String(), nil
+}
+
+// ParseKey reads a key string over the English alphabet.
+func ParseKey(key string) (Cipher, error) {
+	return English.ParseKey(key)
+}
+
+// ParseKey reads a key string over the language's alphabet: the ith letter of the key is the value of the ith letter
+// of the alphabet.
+func (l Language) ParseKey(key string) (Cipher, error) {
+	values := []rune(strings.TrimSpace(key))
+	if len(values) != len(l.Alphabet) {
+		return nil, fmt.Errorf("key string %q has %d letters; want %d", key, len(values), len(l.Alphabet))
+	}
+
+	cipher := make(Cipher, len(values))
+	for i, letter := range l.Alphabet {
+		cipher[string(letter)] = string(values[i])
+	}
+	return cipher, nil
+}
+
+// UnmarshalJSON reads a cipher from a JSON object from keys to values, or from a JSON string holding an English key
+// string. encoding/json writes a cipher as an object with its keys in order. Like encoding/json does for a map, it
+// leaves the cipher as it is for JSON null.
+func (c *Cipher) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var key string
+		if err := json.Unmarshal(data, &key); err != nil {
+			return err
+		}
+		cipher, err := ParseKey(key)
+		if err != nil {
+			return err
+		}
+		*c = cipher
+		return nil
+	}
+
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("reading JSON cipher: %w", err)
+	}
+	*c = Cipher(m)
+	return nil
+}
+
+// sortedKeys returns the cipher's keys, single letters first and then longer keys, each in order.
+func sortedKeys(cipher Cipher) []string {
+	keys := make([]string, 0, len(cipher))
+	for key := range cipher {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if li, lj := utf8.RuneCountInString(keys[i]), utf8.RuneCountInString(keys[j]); li != lj {
+			return li < lj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// FormatMarkdown returns the cipher as a Markdown "Raw | Enc" table with a row for each key.
+func FormatMarkdown(cipher Cipher) string {
+	var table strings.Builder
+	table.WriteString("| Raw | Enc |\n| --- | --- |\n")
+	for _, key := range sortedKeys(cipher) {
+		fmt.Fprintf(&table, "| %s | %s |\n", key, cipher[key])
+	}
+	return table.String()
+}
+
+// ParseMarkdown reads a cipher from the first Markdown table in text, which has a header row, a separator row and
+// then a row of key and value for each key. Text around the table is ignored.
+func ParseMarkdown(text string) (Cipher, error) {
+	cipher := make(Cipher)
+	row := 0
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			if row > 0 {
+				break
+			}
+			continue
+		}
+		row++
+
+		cells := strings.Split(strings.Trim(line, "|"), "|")
+		if len(cells) != 2 || !strings.HasSuffix(line, "|") {
+			return nil, fmt.Errorf("line %d: expected a row of two cells, such as \"| a | o |\"", i+1)
+		}
+		key, value := strings.TrimSpace(cells[0]), strings.TrimSpace(cells[1])
+
+		switch row {
+		case 1: // header
+		case 2:
+			if strings.Trim(key, "-:") != "" || strings.Trim(value, "-:") != "" {
+				return nil, fmt.Errorf("line %d: expected the separator row \"| --- | --- |\"", i+1)
+			}
+		default:
+			if key == "" {
+				return nil, fmt.Errorf("line %d: missing key", i+1)
+			}
+			if _, ok := cipher[key]; ok {
+				return nil, fmt.Errorf("line %d: %q is already in the table", i+1, key)
+			}
+			cipher[key] = value
+		}
+	}
+
+	if row == 0 {
+		return nil, errors.New("no Markdown table")
+	}
+	return cipher, nil
+}
+
+// FormatCycles returns the cipher in cycle notation, such as "(aoe)(bwhy)". Each cycle starts at its first letter
+// in order, and cycles come in order of those letters. When any key has more than one letter, the letters of each
+// cycle are separated by spaces so that they read back the same.
+func FormatCycles(cipher Cipher) (string, error) {
+	all, err := Cycles(cipher)
+	if err != nil {
+		return "", err
+	}
+
+	separator := ""
+	for key := range cipher {
+		if utf8.RuneCountInString(key) > 1 {
+			separator = " "
+			break
+		}
+	}
+
+	var s strings.Builder
+	for _, cycle := range all {
+		s.WriteString("(" + strings.Join(cycle, separator) + ")")
+	}
+	return s.String(), nil
+}
+
+// ParseCycles reads a cipher in cycle notation. The letters of a cycle may be written together, as in "(aoe)", or
+// separated by spaces, as in "(a o e)", which also allows keys of more than one letter. A letter on its own, as in
+// "(a)", encodes to itself.
+func ParseCycles(s string) (Cipher, error) {
+	cipher := make(Cipher)
+	rest := strings.TrimSpace(s)
+	for rest != "" {
+		if rest[0] != '(' {
+			return nil, fmt.Errorf("cycle notation %q: expected \"(\" at %q", s, rest)
+		}
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			return nil, fmt.Errorf("cycle notation %q: missing \")\"", s)
+		}
+		inside := rest[1:end]
+		rest = strings.TrimSpace(rest[end+1:])
+
+		var cycle []string
+		if strings.IndexFunc(inside, unicode.IsSpace) >= 0 {
+			cycle = strings.Fields(inside)
+		} else {
+			for _, r := range inside {
+				cycle = append(cycle, string(r))
+			}
+		}
+		if len(cycle) == 0 || strings.ContainsRune(inside, '(') {
+			return nil, fmt.Errorf("cycle notation %q: empty or nested cycle", s)
+		}
+
+		for i, key := range cycle {
+			if _, ok := cipher[key]; ok {
+				return nil, fmt.Errorf("cycle notation %q: %q is in more than one place", s, key)
+			}
+			cipher[key] = cycle[(i+1)%len(cycle)]
+		}
+	}
+	return cipher, nil
+}
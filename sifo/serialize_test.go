@@ -0,0 +1,210 @@
+package sifo
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func giantCiphers() []Cipher {
+	return []Cipher{LonelyRemarkCipher(), WarmHoldCipher(), WormHelpCipher(), MoonPeerCipher(), WormHeldCipher()}
+}
+
+func TestFormatParseKey(t *testing.T) {
+	key, err := FormatKey(WarmHoldCipher())
+	if err != nil {
+		t.Fatalf("FormatKey() error = %v", err)
+	}
+	if want := "owbnagpyuxvrdmefjltsichzkq"; key != want {
+		t.Errorf("FormatKey(WarmHoldCipher()) = %q; want %q", key, want)
+	}
+
+	for _, cipher := range giantCiphers() {
+		key, err := FormatKey(cipher)
+		if err != nil {
+			t.Fatalf("FormatKey() error = %v", err)
+		}
+		parsed, err := ParseKey(key)
+		if err != nil {
+			t.Fatalf("ParseKey(%q) error = %v", key, err)
+		}
+		if !reflect.DeepEqual(parsed, cipher) {
+			t.Errorf("ParseKey(FormatKey(cipher)) = %v; want %v", parsed, cipher)
+		}
+	}
+
+	if _, err := ParseKey("owbn"); err == nil || !strings.Contains(err.Error(), "has 4 letters; want 26") {
+		t.Errorf("ParseKey() of a short key error = %v", err)
+	}
+	if _, err := FormatKey(Cipher{"a": "b", "b": "a"}); err == nil {
+		t.Errorf("FormatKey() of a partial cipher did not fail")
+	}
+	digraphs := generateRandomDigraphCipher(rand.New(rand.NewSource(1)), English, CommonDigraphs)
+	if _, err := FormatKey(digraphs); err == nil {
+		t.Errorf("FormatKey() of a digraph cipher did not fail")
+	}
+
+	shifted := string(Spanish.Alphabet[1:]) + string(Spanish.Alphabet[0])
+	spanish, err := Spanish.ParseKey(shifted)
+	if err != nil {
+		t.Fatalf("Spanish.ParseKey() error = %v", err)
+	}
+	if key, _ := Spanish.FormatKey(spanish); key != shifted {
+		t.Errorf("Spanish.FormatKey(Spanish.ParseKey(%q)) = %q", shifted, key)
+	}
+	for i, letter := range Spanish.Alphabet[:len(Spanish.Alphabet)-1] {
+		if spanish[string(letter)] != string(Spanish.Alphabet[i+1]) {
+			t.Errorf("Spanish.ParseKey()[%q] = %q; want %q", letter, spanish[string(letter)], Spanish.Alphabet[i+1])
+		}
+	}
+}
+
+func TestCipherJSON(t *testing.T) {
+	digraphs := generateRandomDigraphCipher(rand.New(rand.NewSource(1)), English, CommonDigraphs)
+	for _, cipher := range append(giantCiphers(), digraphs) {
+		data, err := json.Marshal(cipher)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		var parsed Cipher
+		if err := json.Unmarshal(data, &parsed); err != nil {
+			t.Fatalf("json.Unmarshal(%s) error = %v", data, err)
+		}
+		if !reflect.DeepEqual(parsed, cipher) {
+			t.Errorf("json.Unmarshal(%s) = %v; want %v", data, parsed, cipher)
+		}
+	}
+
+	data, _ := json.Marshal(WarmHoldCipher())
+	if !strings.HasPrefix(string(data), `{"a":"o","b":"w","c":"b",`) {
+		t.Errorf("json.Marshal(WarmHoldCipher()) = %s; want its keys in order", data)
+	}
+
+	var fromKey struct{ Cipher Cipher }
+	if err := json.Unmarshal([]byte(`{"Cipher": "owbnagpyuxvrdmefjltsichzkq"}`), &fromKey); err != nil {
+		t.Fatalf("json.Unmarshal() of a key string error = %v", err)
+	}
+	if !reflect.DeepEqual(fromKey.Cipher, WarmHoldCipher()) {
+		t.Errorf("json.Unmarshal() of a key string = %v; want WarmHold", fromKey.Cipher)
+	}
+
+	var null struct{ Cipher Cipher }
+	if err := json.Unmarshal([]byte(`{"Cipher": null}`), &null); err != nil || null.Cipher != nil {
+		t.Errorf("json.Unmarshal() of null = %v, %v; want a nil cipher", null.Cipher, err)
+	}
+	kept := WarmHoldCipher()
+	if err := json.Unmarshal([]byte(`null`), &kept); err != nil || !reflect.DeepEqual(kept, WarmHoldCipher()) {
+		t.Errorf("json.Unmarshal() of null into a cipher = %v, %v; want the cipher unchanged", kept, err)
+	}
+
+	for _, bad := range []string{`["a", "o"]`, `"owbn"`, `42`} {
+		var cipher Cipher
+		if err := json.Unmarshal([]byte(bad), &cipher); err == nil {
+			t.Errorf("json.Unmarshal(%s) did not fail", bad)
+		}
+	}
+}
+
+func TestFormatParseMarkdown(t *testing.T) {
+	cipher := Cipher{"a": "b", "b": "a", "th": "qu", "qu": "th"}
+	want := "| Raw | Enc |\n| --- | --- |\n| a | b |\n| b | a |\n| qu | th |\n| th | qu |\n"
+	if table := FormatMarkdown(cipher); table != want {
+		t.Errorf("FormatMarkdown() = %q; want %q", table, want)
+	}
+
+	for _, cipher := range append(giantCiphers(), cipher) {
+		parsed, err := ParseMarkdown("Best cipher:\n\n" + FormatMarkdown(cipher) + "\nMore text.\n")
+		if err != nil {
+			t.Fatalf("ParseMarkdown() error = %v", err)
+		}
+		if !reflect.DeepEqual(parsed, cipher) {
+			t.Errorf("ParseMarkdown(FormatMarkdown(cipher)) = %v; want %v", parsed, cipher)
+		}
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"no table here", "no Markdown table"},
+		{"| Raw | Enc |\n| a | b |\n", "line 2: expected the separator row"},
+		{"| Raw | Enc |\n| --- | --- |\n| a | b | c |\n", "line 3: expected a row of two cells"},
+		{"| Raw | Enc |\n| --- | --- |\n| a | b |\n| a | c |\n", `line 4: "a" is already in the table`},
+	}
+	for _, test := range tests {
+		if _, err := ParseMarkdown(test.input); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("ParseMarkdown(%q) error = %v; want it to contain %q", test.input, err, test.want)
+		}
+	}
+}
+
+func TestParseMarkdownReadme(t *testing.T) {
+	readme, err := os.ReadFile("../README.md")
+	if err != nil {
+		t.Fatalf("reading README.md: %v", err)
+	}
+	i := strings.Index(string(readme), "| Raw | Enc |")
+	if i < 0 {
+		t.Fatalf("README.md has no Raw | Enc table")
+	}
+
+	cipher, err := ParseMarkdown(string(readme[i:]))
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+	if !reflect.DeepEqual(cipher, WarmHoldCipher()) {
+		t.Errorf("ParseMarkdown() of the README table = %v; want WarmHold", cipher)
+	}
+}
+
+func TestFormatParseCycles(t *testing.T) {
+	tests := []struct {
+		cipher Cipher
+		want   string
+	}{
+		{Cipher{"a": "b", "b": "c", "c": "a", "d": "e", "e": "d"}, "(abc)(de)"},
+		{Cipher{"a": "b", "b": "a", "th": "qu", "qu": "th"}, "(a b)(qu th)"},
+		{Cipher{"n": "ñ", "ñ": "n"}, "(nñ)"},
+	}
+	for _, test := range tests {
+		s, err := FormatCycles(test.cipher)
+		if err != nil {
+			t.Fatalf("FormatCycles(%v) error = %v", test.cipher, err)
+		}
+		if s != test.want {
+			t.Errorf("FormatCycles(%v) = %q; want %q", test.cipher, s, test.want)
+		}
+	}
+
+	for _, cipher := range giantCiphers() {
+		s, err := FormatCycles(cipher)
+		if err != nil {
+			t.Fatalf("FormatCycles() error = %v", err)
+		}
+		parsed, err := ParseCycles(s)
+		if err != nil {
+			t.Fatalf("ParseCycles(%q) error = %v", s, err)
+		}
+		if !reflect.DeepEqual(parsed, cipher) {
+			t.Errorf("ParseCycles(FormatCycles(cipher)) = %v; want %v", parsed, cipher)
+		}
+	}
+
+	if parsed, err := ParseCycles(" (a b c) (de) (f)"); err != nil || !reflect.DeepEqual(parsed, Cipher{"a": "b", "b": "c", "c": "a", "d": "e", "e": "d", "f": "f"}) {
+		t.Errorf("ParseCycles() with spaces = %v, %v", parsed, err)
+	}
+
+	for _, bad := range []string{"(ab", "a(b)", "(ab)(bc)", "(aba)", "()", "(a(b))"} {
+		if _, err := ParseCycles(bad); err == nil {
+			t.Errorf("ParseCycles(%q) did not fail", bad)
+		}
+	}
+	for _, bad := range []Cipher{{"a": "b", "b": "c"}, {"a": "c", "b": "c", "c": "a"}} {
+		if _, err := FormatCycles(bad); err == nil || !strings.Contains(err.Error(), "not a permutation") {
+			t.Errorf("FormatCycles(%v) error = %v; want it not to be a permutation", bad, err)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package sifo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// streamRunLimit is how many bytes of a run of letters an Encoder holds back waiting for the run to end. A longer
+	// run, as in a file with no spaces, is encoded as it arrives, keeping back only the letters the cipher has to see
+	// ahead of the next match.
+	streamRunLimit = 64 << 10
+
+	// streamBufferSize is how many bytes a Decoder reads from its reader at a time.
+	streamBufferSize = 32 << 10
+)
+
+// ErrClosed is returned by writes to an Encoder that has been closed.
+var ErrClosed = errors.New("encoder is closed")
+
+// Encoder encodes text written to it like EncodeText and writes the encoding to an underlying writer, holding only
+// the text it cannot encode yet in memory: a run of letters that may go on in the next write, for which a
+// multi-letter key may match across the boundary, and a UTF-8 sequence split between writes. Close must be called to
+// encode what is held back.
+type Encoder struct {
+	w         io.Writer
+	cipher    Cipher
+	lang      Language
+	keyLength int    // letters in the longest key
+	lookahead int    // letters encodeLetters needs to see to encode the start of a run
+	pending   []byte // text written but not yet encoded
+	letters   int    // bytes at the start of pending known to be letters
+	err       error
+}
+
+// NewEncoder returns an Encoder that writes the encoding of English text to w. If the cipher is not valid (see
+// Validate), writes and Close return the validation error and nothing is written.
+func NewEncoder(w io.Writer, cipher Cipher) *Encoder {
+	return English.NewEncoder(w, cipher)
+}
+
+// NewEncoder returns an Encoder that writes the encoding of text in the language to w, once the cipher is validated
+// over the language's alphabet.
+func (l Language) NewEncoder(w io.Writer, cipher Cipher) *Encoder {
+	if err := l.check(cipher); err != nil {
+		return &Encoder{err: err}
+	}
+	return l.newEncoder(w, cipher)
+}
+
+// newEncoder returns an Encoder that takes any cipher, for the Decoder, whose inverse ciphers are checked by Inverse
+// instead.
+func (l Language) newEncoder(w io.Writer, cipher Cipher) *Encoder {
+	keyLength := longestKey(cipher)
+	return &Encoder{w: w, cipher: cipher, lang: l, keyLength: keyLength, lookahead: 2*keyLength + 1}
+}
+
+// Write encodes as much of p as it can and writes the encoding to the underlying writer.
+func (e *Encoder) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	e.pending = append(e.pending, p...)
+	if err := e.flush(false); err != nil {
+		e.err = err
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close encodes the text held back and writes it to the underlying writer, which it does not close.
+func (e *Encoder) Close() error {
+	if e.err != nil {
+		if errors.Is(e.err, ErrClosed) {
+			return nil
+		}
+		return e.err
+	}
+	err := e.flush(true)
+	e.err = ErrClosed
+	return err
+}
+
+// flush encodes the pending text up to the run of letters at its end, which may go on in the next write, and writes
+// the encoding. A run longer than streamRunLimit is encoded up to its last lookahead letters. When final, the
+// pending text is complete and all of it is encoded.
+func (e *Encoder) flush(final bool) error {
+	text := e.pending
+	end, start := len(text), len(text)
+	if !final {
+		end = completeRunes(text)
+		// Only the text written since the last flush needs to be looked at.
+		start = end
+		for start > e.letters {
+			r, size := utf8.DecodeLastRune(text[e.letters:start])
+			if !unicode.IsLetter(r) {
+				break
+			}
+			start -= size
+		}
+		if start == e.letters {
+			start = 0
+		}
+	}
+
+	encoded := e.lang.encodeRuns(string(text[:start]), e.cipher)
+	if end-start > streamRunLimit {
+		run, n := e.lang.encodeLetters(string(text[start:end]), e.cipher, e.keyLength, e.lookahead)
+		encoded += run
+		start += n
+	}
+	if start > 0 {
+		e.pending = append(e.pending[:0], text[start:]...)
+	}
+	e.letters = end - start
+
+	_, err := io.WriteString(e.w, encoded)
+	return err
+}
+
+// completeRunes returns the length of p without a UTF-8 sequence cut off at its end.
+func completeRunes(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if utf8.FullRune(p[i:]) {
+				return len(p)
+			}
+			return i
+		}
+	}
+	return len(p)
+}
+
+// Decoder decodes text read from an underlying reader like Decode, a buffer at a time.
+type Decoder struct {
+	r       io.Reader
+	encoder *Encoder // encodes with the inverse cipher into decoded
+	decoded bytes.Buffer
+	buf     []byte
+	err     error
+}
+
+// NewDecoder returns a Decoder that decodes English text read from r. If the cipher cannot be decoded
+// unambiguously, reads return an error wrapping ErrAmbiguousCipher.
+func NewDecoder(r io.Reader, cipher Cipher) *Decoder {
+	return English.NewDecoder(r, cipher)
+}
+
+// NewDecoder returns a Decoder that decodes text in the language read from r.
+func (l Language) NewDecoder(r io.Reader, cipher Cipher) *Decoder {
+	d := &Decoder{r: r}
+	inverse, err := Inverse(cipher)
+	if err != nil {
+		d.err = err
+		return d
+	}
+	d.encoder = l.newEncoder(&d.decoded, inverse)
+	return d
+}
+
+// Read reads decoded text into p.
+func (d *Decoder) Read(p []byte) (int, error) {
+	for d.decoded.Len() == 0 && d.err == nil {
+		if d.buf == nil {
+			d.buf = make([]byte, streamBufferSize)
+		}
+		n, err := d.r.Read(d.buf)
+		// Writing to a bytes.Buffer does not fail.
+		d.encoder.Write(d.buf[:n])
+		if errors.Is(err, io.EOF) {
+			d.encoder.Close()
+			d.err = io.EOF
+		} else if err != nil {
+			d.err = err
+		}
+	}
+	if d.decoded.Len() > 0 {
+		return d.decoded.Read(p)
+	}
+	return 0, d.err
+}
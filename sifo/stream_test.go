@@ -0,0 +1,136 @@
+package sifo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// writeChunks writes text to an encoder size bytes at a time, splitting words and UTF-8 sequences.
+func writeChunks(t *testing.T, enc *Encoder, text string, size int) {
+	t.Helper()
+	for len(text) > 0 {
+		n := min(size, len(text))
+		if _, err := enc.Write([]byte(text[:n])); err != nil {
+			t.Fatalf("Encoder.Write() error = %v", err)
+		}
+		text = text[n:]
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Encoder.Close() error = %v", err)
+	}
+}
+
+// The test ciphers encode only the letters of these alphabets, so they are validated over them.
+var (
+	multiLetters = Language{Name: "multi", Alphabet: []rune("abcdeht"), Vowels: []rune("ae")}
+	accented     = Language{Name: "accented", Alphabet: []rune("aeñéü"), Vowels: []rune("aeéü")}
+)
+
+func TestEncoder(t *testing.T) {
+	multi := Cipher{"a": "b", "b": "ca", "c": "cb", "th": "a", "t": "cc", "h": "cd", "d": "ce", "e": "d"}
+	tests := []struct {
+		name   string
+		text   string
+		lang   Language
+		cipher Cipher
+	}{
+		{"single letters", "Here's the warm-hold test,\n\tread twice.", English, WarmHoldCipher()},
+		{"multiple letters", "The bath: death to Teeth, the cat. THE END", multiLetters, multi},
+		{"utf-8", "Ñandú en el árbol, ñu y Über.", accented, Cipher{"ñ": "é", "a": "ü", "e": "a", "ü": "ñ", "é": "e"}},
+		{"no letters", "1, 2, 3...", multiLetters, multi},
+		{"empty", "", multiLetters, multi},
+	}
+
+	for _, test := range tests {
+		want := test.lang.EncodeText(test.text, test.cipher)
+		for _, size := range []int{1, 2, 3, 7, len(test.text) + 1} {
+			var buf bytes.Buffer
+			writeChunks(t, test.lang.NewEncoder(&buf, test.cipher), test.text, size)
+			if buf.String() != want {
+				t.Errorf("%s: Encoder in writes of %d bytes = %q; want %q", test.name, size, buf.String(), want)
+			}
+		}
+	}
+}
+
+func TestEncoderLongRun(t *testing.T) {
+	// A run of letters longer than the encoder holds back is encoded before it ends, and a key may span the point
+	// where it is cut.
+	cipher := Cipher{"a": "b", "b": "ca", "c": "cb", "th": "a", "t": "cc", "h": "cd", "d": "ce", "e": "d"}
+	text := strings.Repeat("Bathed", streamRunLimit/3) + " the end"
+
+	var buf bytes.Buffer
+	enc := multiLetters.NewEncoder(&buf, cipher)
+	writeChunks(t, enc, text, 4099)
+	if want := EncodeText(text, cipher); buf.String() != want {
+		t.Errorf("Encoder of a %d-letter run differs from EncodeText()", len(text))
+	}
+	if cap(enc.pending) > 2*streamRunLimit {
+		t.Errorf("Encoder held %d bytes back; want no more than %d", cap(enc.pending), 2*streamRunLimit)
+	}
+}
+
+func TestEncoderClosed(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, WarmHoldCipher())
+	enc.Write([]byte("war"))
+	if buf.Len() != 0 {
+		t.Errorf("Encoder wrote %q before the word ended", buf.String())
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Encoder.Close() error = %v", err)
+	}
+	if buf.String() != "hol" {
+		t.Errorf("Encoder.Close() wrote %q; want %q", buf.String(), "hol")
+	}
+	if _, err := enc.Write([]byte("m")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Encoder.Write() after Close() error = %v; want ErrClosed", err)
+	}
+}
+
+func TestEncoderValidates(t *testing.T) {
+	bad := WarmHoldCipher()
+	bad["a"] = "a"
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, bad)
+	var validation *ValidationError
+	if _, err := enc.Write([]byte("warm ")); !errors.As(err, &validation) {
+		t.Errorf("Encoder.Write() with an invalid cipher error = %v; want a *ValidationError", err)
+	}
+	if err := enc.Close(); !errors.As(err, &validation) {
+		t.Errorf("Encoder.Close() with an invalid cipher error = %v; want a *ValidationError", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Encoder with an invalid cipher wrote %q", buf.String())
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	cipher := Cipher{"a": "b", "b": "ca", "c": "cb", "th": "a", "t": "cc", "h": "cd", "d": "ce", "e": "d"}
+	text := "The bath: death to Teeth, the cat.\n" + strings.Repeat("bathed", 20000)
+	encoded := EncodeText(text, cipher)
+
+	readers := map[string]io.Reader{
+		"whole":    strings.NewReader(encoded),
+		"one byte": iotest.OneByteReader(strings.NewReader(encoded)),
+		"half":     iotest.HalfReader(strings.NewReader(encoded)),
+	}
+	for name, r := range readers {
+		decoded, err := io.ReadAll(NewDecoder(r, cipher))
+		if err != nil {
+			t.Fatalf("%s: Decoder error = %v", name, err)
+		}
+		if string(decoded) != text {
+			t.Errorf("%s: Decoder did not restore the text", name)
+		}
+	}
+
+	if _, err := io.ReadAll(NewDecoder(strings.NewReader("xy"), Cipher{"a": "x", "b": "xy"})); !errors.Is(err, ErrAmbiguousCipher) {
+		t.Errorf("Decoder with an ambiguous cipher error = %v; want ErrAmbiguousCipher", err)
+	}
+}
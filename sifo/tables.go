@@ -0,0 +1,225 @@
+package sifo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// The file names of the feature tables shipped next to words.csv.
+const (
+	PrefixesFile     = "prefixes.csv"
+	SuffixesFile     = "suffixes.csv"
+	MiddlesFile      = "middles.csv"
+	PartialWordsFile = "partial_words.csv"
+)
+
+// FeatureTables are feature counts precomputed from a word list and saved in the "Partial Word,Count" format, so that
+// a Dictionary's prefixes, suffixes and middles can be reviewed, edited and loaded instead of recomputed.
+type FeatureTables struct {
+	Prefixes     map[string]int64
+	Suffixes     map[string]int64
+	Middles      map[string]int64
+	PartialWords map[string]int64 // every substring of every word (see CreatePartialWordDictionary)
+}
+
+// TableLimits is how many of the most common features each table keeps. Zero keeps them all.
+type TableLimits struct {
+	Prefixes     int
+	Suffixes     int
+	Middles      int
+	PartialWords int
+}
+
+// DefaultTableLimits are the sizes of the shipped tables.
+var DefaultTableLimits = TableLimits{Prefixes: 400, Suffixes: 400, Middles: 1307}
+
+// NewFeatureTables counts the features of words in the language, keeping the most common of each up to limits.
+// Features are counted in lower case, so "TRUE" in a word list counts towards "true".
+func NewFeatureTables(words map[string]int64, lang Language, limits TableLimits) FeatureTables {
+	lang = lang.orDefault()
+	words = lang.lowerCounts(words)
+	prefixes, suffixes := lang.PrefixAndSuffixCounts(words)
+
+	return FeatureTables{
+		Prefixes:     topCounts(prefixes, limits.Prefixes),
+		Suffixes:     topCounts(suffixes, limits.Suffixes),
+		Middles:      topCounts(lang.MiddleCounts(words), limits.Middles),
+		PartialWords: topCounts(lang.CreatePartialWordDictionary(words), limits.PartialWords),
+	}
+}
+
+// lowerCounts returns the counts with every word in lower case, adding up the counts of words that differ only in
+// case.
+func (l Language) lowerCounts(words map[string]int64) map[string]int64 {
+	lowered := make(map[string]int64, len(words))
+	for word, count := range words {
+		lowered[l.ToLower(word)] += count
+	}
+	return lowered
+}
+
+// topCounts returns the n most common entries of counts, breaking ties alphabetically. n of 0 returns counts.
+func topCounts(counts map[string]int64, n int) map[string]int64 {
+	if n <= 0 || n >= len(counts) {
+		return counts
+	}
+
+	sorted := make([]string, 0, len(counts))
+	for key := range counts {
+		sorted = append(sorted, key)
+	}
+	sortByCount(sorted, counts)
+
+	top := make(map[string]int64, n)
+	for _, key := range sorted[:n] {
+		top[key] = counts[key]
+	}
+	return top
+}
+
+// LoadFeatureTables reads the prefix, suffix, middle and partial word tables from dir.
+func LoadFeatureTables(dir string) (FeatureTables, error) {
+	var tables FeatureTables
+	var err error
+
+	if tables.Prefixes, err = LoadCounts(filepath.Join(dir, PrefixesFile)); err != nil {
+		return FeatureTables{}, err
+	}
+	if tables.Suffixes, err = LoadCounts(filepath.Join(dir, SuffixesFile)); err != nil {
+		return FeatureTables{}, err
+	}
+	if tables.Middles, err = LoadCounts(filepath.Join(dir, MiddlesFile)); err != nil {
+		return FeatureTables{}, err
+	}
+	if tables.PartialWords, err = LoadCounts(filepath.Join(dir, PartialWordsFile)); err != nil {
+		return FeatureTables{}, err
+	}
+
+	return tables, nil
+}
+
+// Save writes the tables to dir, most common features first.
+func (t FeatureTables) Save(dir string) error {
+	tables := []struct {
+		filename string
+		counts   map[string]int64
+	}{
+		{PrefixesFile, t.Prefixes},
+		{SuffixesFile, t.Suffixes},
+		{MiddlesFile, t.Middles},
+		{PartialWordsFile, t.PartialWords},
+	}
+
+	for _, table := range tables {
+		if err := SaveCounts(filepath.Join(dir, table.filename), table.counts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewDictionaryFromTables is NewDictionary with the prefixes, suffixes and middles taken from tables rather than
+// counted from words. Any table that is nil is counted from words instead. The word patterns, groups, boundaries and
+// anti sets are always built from words.
+//
+// Only complete tables give the dictionary NewDictionary builds. Tables trimmed to their most common features, as the
+// shipped ones are, give an approximation of it: rarer prefixes, suffixes and middles are missing, so encodings that
+// use them score lower, and tables counted from another word list than words describe that list instead.
+func NewDictionaryFromTables(words map[string]int64, tables FeatureTables, lang Language) Dictionary {
+	counted := NewCountedDictionary(words, lang)
+	if tables.Prefixes != nil {
+		counted.Prefixes = tables.Prefixes
+	}
+	if tables.Suffixes != nil {
+		counted.Suffixes = tables.Suffixes
+	}
+	if tables.Middles != nil {
+		counted.Middles = tables.Middles
+	}
+
+	return counted.Dictionary(MinSupport{})
+}
+
+// LoadCounts reads a feature table from a file.
+func LoadCounts(filename string) (map[string]int64, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	counts, err := ReadCounts(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return counts, nil
+}
+
+// ReadCounts reads a feature table of "feature,count" records with an optional header. Unlike ReadWords, a malformed
+// or repeated line is an error, since a table that does not read cleanly cannot be trusted.
+func ReadCounts(r io.Reader) (map[string]int64, error) {
+	var report ReadReport
+	counts, err := ReadWords(r, ReadOptions{Format: FormatCSV, Report: &report})
+	if err != nil {
+		return nil, err
+	}
+	switch len(report.Skipped) {
+	case 0:
+	case 1:
+		return nil, fmt.Errorf("%s", report.Skipped[0])
+	default:
+		return nil, fmt.Errorf("%s (and %d more)", report.Skipped[0], len(report.Skipped)-1)
+	}
+
+	return counts, nil
+}
+
+// SaveCounts writes a feature table to a file.
+func SaveCounts(filename string, counts map[string]int64) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteCounts(file, counts); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// WriteCounts writes a feature table in the "Partial Word,Count" format, most common first.
+func WriteCounts(w io.Writer, counts map[string]int64) error {
+	return writeCounts(w, []string{"Partial Word", "Count"}, counts)
+}
+
+// writeCounts writes the header and then the counts as CSV, most common first and ties in alphabetical order, so
+// that regenerating a file from the same input changes nothing.
+func writeCounts(w io.Writer, header []string, counts map[string]int64) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	sorted := make([]string, 0, len(counts))
+	for key := range counts {
+		sorted = append(sorted, key)
+	}
+	sortByCount(sorted, counts)
+
+	for _, key := range sorted {
+		if err := writer.Write([]string{key, strconv.FormatInt(counts[key], 10)}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,150 @@
+package sifo
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteReadCounts(t *testing.T) {
+	counts := map[string]int64{"th": 95, "an": 34, "he": 34, "ñu": 1}
+
+	var buf bytes.Buffer
+	if err := WriteCounts(&buf, counts); err != nil {
+		t.Fatalf("WriteCounts() error = %v", err)
+	}
+
+	want := "Partial Word,Count\nth,95\nan,34\nhe,34\nñu,1\n"
+	if buf.String() != want {
+		t.Errorf("WriteCounts() wrote %q; want %q", buf.String(), want)
+	}
+
+	got, err := ReadCounts(&buf)
+	if err != nil {
+		t.Fatalf("ReadCounts() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, counts) {
+		t.Errorf("ReadCounts() = %v; want %v", got, counts)
+	}
+}
+
+func TestReadCountsMalformed(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Partial Word,Count\nth,95\nan,lots\n", "count is not an integer"},
+		{"Partial Word,Count\nth,95\nth,96\nan\n", `duplicate of line 2 ("th,96") (and 1 more)`},
+	}
+
+	for _, test := range tests {
+		_, err := ReadCounts(strings.NewReader(test.input))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("ReadCounts(%q) error = %v; want it to contain %q", test.input, err, test.want)
+		}
+	}
+}
+
+func TestTopCounts(t *testing.T) {
+	counts := map[string]int64{"a": 5, "b": 3, "c": 3, "d": 1}
+
+	if got, want := topCounts(counts, 2), map[string]int64{"a": 5, "b": 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("topCounts(%v, 2) = %v; want %v", counts, got, want)
+	}
+	if got := topCounts(counts, 0); !reflect.DeepEqual(got, counts) {
+		t.Errorf("topCounts(%v, 0) = %v; want all counts", counts, got)
+	}
+}
+
+func TestFeatureTablesSaveLoad(t *testing.T) {
+	words := map[string]int64{"street": 10, "strong": 4, "beautiful": 2}
+	tables := NewFeatureTables(words, English, TableLimits{Prefixes: 2})
+
+	if len(tables.Prefixes) != 2 || tables.Prefixes["str"] != 14 || tables.Prefixes["st"] != 14 {
+		t.Errorf("NewFeatureTables() prefixes = %v; want st and str", tables.Prefixes)
+	}
+
+	dir := t.TempDir()
+	if err := tables.Save(dir); err != nil {
+		t.Fatalf("FeatureTables.Save() error = %v", err)
+	}
+	loaded, err := LoadFeatureTables(dir)
+	if err != nil {
+		t.Fatalf("LoadFeatureTables() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, tables) {
+		t.Errorf("LoadFeatureTables() = %v; want %v", loaded, tables)
+	}
+}
+
+func TestNewDictionaryFromTables(t *testing.T) {
+	words := map[string]int64{"street": 10, "strong": 4, "beautiful": 2}
+
+	// Complete tables assemble the same dictionary as the word list.
+	full := NewFeatureTables(words, English, TableLimits{})
+	if !reflect.DeepEqual(NewDictionaryFromTables(words, full, English), NewDictionary(words, English)) {
+		t.Errorf("NewDictionaryFromTables() with complete tables differs from NewDictionary()")
+	}
+
+	// Trimmed tables limit the sets to what the tables hold.
+	trimmed := NewFeatureTables(words, English, TableLimits{Prefixes: 2})
+	dict := NewDictionaryFromTables(words, FeatureTables{Prefixes: trimmed.Prefixes}, English)
+	if !reflect.DeepEqual(dict.Prefixes, map[string]bool{"st": true, "str": true}) {
+		t.Errorf("NewDictionaryFromTables() prefixes = %v; want st and str", dict.Prefixes)
+	}
+	if !dict.Suffixes["eet"] {
+		t.Errorf("NewDictionaryFromTables() without a suffix table is missing suffix %q", "eet")
+	}
+}
+
+func TestNewFeatureTablesLowerCase(t *testing.T) {
+	tables := NewFeatureTables(map[string]int64{"TRUE": 3, "true": 1}, English, TableLimits{})
+
+	if tables.Prefixes["tr"] != 4 || tables.PartialWords["true"] != 4 {
+		t.Errorf("NewFeatureTables() = %v; want TRUE counted as true", tables)
+	}
+	if _, ok := tables.PartialWords["TRUE"]; ok {
+		t.Errorf("NewFeatureTables() kept upper case partial word TRUE")
+	}
+}
+
+func TestShippedFeatureTables(t *testing.T) {
+	tables, err := LoadFeatureTables("..")
+	if err != nil {
+		t.Fatalf("LoadFeatureTables() error = %v", err)
+	}
+
+	sizes := []struct {
+		name  string
+		table map[string]int64
+		want  int
+	}{
+		{PrefixesFile, tables.Prefixes, DefaultTableLimits.Prefixes},
+		{SuffixesFile, tables.Suffixes, DefaultTableLimits.Suffixes},
+		{MiddlesFile, tables.Middles, DefaultTableLimits.Middles},
+	}
+	for _, size := range sizes {
+		if len(size.table) != size.want {
+			t.Errorf("%s has %d entries; want %d", size.name, len(size.table), size.want)
+		}
+	}
+	if tables.Prefixes["th"] == 0 || tables.Suffixes["ed"] == 0 || tables.PartialWords["the"] == 0 {
+		t.Errorf("the shipped feature tables are missing common features")
+	}
+
+	words, err := LoadCounts("../words.csv")
+	if err != nil {
+		t.Fatalf("LoadCounts() error = %v", err)
+	}
+	if fresh := NewFeatureTables(words, English, DefaultTableLimits); !reflect.DeepEqual(tables, fresh) {
+		t.Errorf("the shipped feature tables are stale; regenerate them with go run ./cmd/features")
+	}
+
+	// The trimmed prefix table approximates the word list's prefixes: it keeps the most common ones only.
+	approx := NewDictionaryFromTables(words, FeatureTables{Prefixes: tables.Prefixes}, English)
+	if exact := NewDictionary(words, English); len(approx.Prefixes) >= len(exact.Prefixes) {
+		t.Errorf("NewDictionaryFromTables() has %d prefixes; want fewer than the %d of NewDictionary()",
+			len(approx.Prefixes), len(exact.Prefixes))
+	}
+}
@@ -0,0 +1,150 @@
+package sifo
+
+import (
+	"unicode/utf8"
+)
+
+// prefixWeight is the share of a word's occurrence score given when its encoding is not a word but spells all of the
+// start of one. An encoding that is a valid prefix for only part of its length gets that part of the weight.
+const prefixWeight = 2.0
+
+// substringWeight is the share of a word's occurrence score given when its encoding is not a word but occurs whole
+// inside one, as "tre" does in "street". An encoding of which only a part occurs inside a word gets that part of the
+// weight.
+const substringWeight = 1.0
+
+// Trie is a prefix tree over a word list. It answers whether a string starts any word, how many words it starts and
+// how much of a string is a valid prefix, each in one walk of the string. Built over the partial words of
+// CreatePartialWordDictionary, where every substring of a word is itself an entry, the same queries answer whether a
+// string occurs anywhere inside a word.
+//
+// Nodes are numbered and edges kept in a single map keyed by node and letter, which keeps the trie compact and its
+// lookups as cheap as the map lookups Score already does.
+type Trie struct {
+	edges    map[trieEdge]int32
+	prefixes []int32 // number of words starting with each node's prefix
+	ends     []bool  // whether each node's prefix is itself a word
+}
+
+type trieEdge struct {
+	node   int32
+	letter rune
+}
+
+// NewTrie builds a trie over the words.
+func NewTrie(words map[string]int64) *Trie {
+	t := &Trie{
+		edges:    make(map[trieEdge]int32),
+		prefixes: []int32{0},
+		ends:     []bool{false},
+	}
+	for word := range words {
+		t.Insert(word)
+	}
+	return t
+}
+
+// Insert adds a word to the trie. Inserting a word already in the trie does nothing.
+func (t *Trie) Insert(word string) {
+	if t.Contains(word) {
+		return
+	}
+
+	node := int32(0)
+	t.prefixes[node]++
+	for _, letter := range word {
+		edge := trieEdge{node, letter}
+		next, ok := t.edges[edge]
+		if !ok {
+			next = int32(len(t.prefixes))
+			t.edges[edge] = next
+			t.prefixes = append(t.prefixes, 0)
+			t.ends = append(t.ends, false)
+		}
+		node = next
+		t.prefixes[node]++
+	}
+	t.ends[node] = true
+}
+
+// walk follows s from the root and returns the last node reached and how many bytes of s it took to get there.
+func (t *Trie) walk(s string) (node int32, n int) {
+	for i, letter := range s {
+		next, ok := t.edges[trieEdge{node, letter}]
+		if !ok {
+			return node, i
+		}
+		node = next
+	}
+	return node, len(s)
+}
+
+// Contains reports whether word is in the trie.
+func (t *Trie) Contains(word string) bool {
+	node, n := t.walk(word)
+	return n == len(word) && t.ends[node]
+}
+
+// HasPrefix reports whether any word in the trie starts with prefix.
+func (t *Trie) HasPrefix(prefix string) bool {
+	_, n := t.walk(prefix)
+	return n == len(prefix) && t.prefixes[0] > 0
+}
+
+// CountWithPrefix returns the number of words in the trie that start with prefix.
+func (t *Trie) CountWithPrefix(prefix string) int {
+	node, n := t.walk(prefix)
+	if n != len(prefix) {
+		return 0
+	}
+	return int(t.prefixes[node])
+}
+
+// LongestPrefix returns the longest start of s that is also the start of some word in the trie.
+func (t *Trie) LongestPrefix(s string) string {
+	_, n := t.walk(s)
+	return s[:n]
+}
+
+// LongestSubstring returns the longest part of s that is also the start of some word in the trie. Over partial words
+// that is the longest part of s found anywhere inside a word: "xtrez" gives "tre" for a trie over the partial words of
+// "street".
+func (t *Trie) LongestSubstring(s string) string {
+	longest := ""
+	for i := range s {
+		if len(s)-i <= len(longest) {
+			break
+		}
+		if _, n := t.walk(s[i:]); n > len(longest) {
+			longest = s[i : i+n]
+		}
+	}
+	return longest
+}
+
+// Len returns the number of words in the trie.
+func (t *Trie) Len() int {
+	return int(t.prefixes[0])
+}
+
+// prefixDepth returns how much of word, from 0 to 1, stays a valid prefix of a word in the trie. "strx" is 0.75
+// because "str" starts words and "strx" does not.
+func prefixDepth(word string, trie *Trie) float64 {
+	length := utf8.RuneCountInString(word)
+	if length == 0 {
+		return 0
+	}
+	prefix := utf8.RuneCountInString(trie.LongestPrefix(word))
+	return float64(prefix) / float64(length)
+}
+
+// substringDepth returns how much of word, from 0 to 1, occurs inside a word, using a trie over partial words. "xtre"
+// is 0.75 because "tre" occurs in "street" and "xtre" occurs in no word.
+func substringDepth(word string, partialWords *Trie) float64 {
+	length := utf8.RuneCountInString(word)
+	if length == 0 {
+		return 0
+	}
+	substring := utf8.RuneCountInString(partialWords.LongestSubstring(word))
+	return float64(substring) / float64(length)
+}
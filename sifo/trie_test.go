@@ -0,0 +1,169 @@
+package sifo
+
+import (
+	"testing"
+)
+
+func TestTrie(t *testing.T) {
+	trie := NewTrie(map[string]int64{"street": 10, "strong": 4, "string": 2, "stop": 1, "año": 1})
+
+	tests := []struct {
+		query    string
+		contains bool
+		prefix   bool
+		count    int
+		longest  string
+	}{
+		{"", false, true, 5, ""},
+		{"s", false, true, 4, "s"},
+		{"str", false, true, 3, "str"},
+		{"stro", false, true, 1, "stro"},
+		{"strong", true, true, 1, "strong"},
+		{"strongly", false, false, 0, "strong"},
+		{"strx", false, false, 0, "str"},
+		{"xyz", false, false, 0, ""},
+		{"añ", false, true, 1, "añ"},
+		{"año", true, true, 1, "año"},
+		{"añx", false, false, 0, "añ"},
+	}
+
+	for _, test := range tests {
+		if got := trie.Contains(test.query); got != test.contains {
+			t.Errorf("Contains(%q) = %v; want %v", test.query, got, test.contains)
+		}
+		if got := trie.HasPrefix(test.query); got != test.prefix {
+			t.Errorf("HasPrefix(%q) = %v; want %v", test.query, got, test.prefix)
+		}
+		if got := trie.CountWithPrefix(test.query); got != test.count {
+			t.Errorf("CountWithPrefix(%q) = %d; want %d", test.query, got, test.count)
+		}
+		if got := trie.LongestPrefix(test.query); got != test.longest {
+			t.Errorf("LongestPrefix(%q) = %q; want %q", test.query, got, test.longest)
+		}
+	}
+}
+
+func TestTrieInsertDuplicate(t *testing.T) {
+	trie := NewTrie(map[string]int64{"stop": 1})
+	trie.Insert("stop")
+	trie.Insert("st")
+
+	if trie.Len() != 2 {
+		t.Errorf("Len() = %d; want 2", trie.Len())
+	}
+	if got := trie.CountWithPrefix("st"); got != 2 {
+		t.Errorf("CountWithPrefix(%q) = %d; want 2", "st", got)
+	}
+	if !trie.Contains("st") {
+		t.Errorf("Contains(%q) = false after inserting it", "st")
+	}
+}
+
+func TestTrieSubstrings(t *testing.T) {
+	words := map[string]int64{"street": 10}
+	trie := NewTrie(CreatePartialWordDictionary(words))
+
+	for _, substring := range []string{"tre", "eet", "street", "e"} {
+		if !trie.HasPrefix(substring) {
+			t.Errorf("HasPrefix(%q) over partial words = false; want true", substring)
+		}
+	}
+	if trie.HasPrefix("ts") {
+		t.Errorf("HasPrefix(%q) over partial words = true; want false", "ts")
+	}
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"xtrez", "tre"},
+		{"street", "street"},
+		{"tsree", "ree"},
+		{"xyz", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := trie.LongestSubstring(test.query); got != test.expected {
+			t.Errorf("LongestSubstring(%q) = %q; want %q", test.query, got, test.expected)
+		}
+	}
+}
+
+func TestSubstringDepth(t *testing.T) {
+	trie := NewTrie(CreatePartialWordDictionary(map[string]int64{"street": 10, "año": 1}))
+
+	tests := []struct {
+		word     string
+		expected float64
+	}{
+		{"xtre", 0.75},
+		{"reet", 1},
+		{"xañ", 2.0 / 3},
+		{"xyz", 0},
+		{"", 0},
+	}
+
+	for _, test := range tests {
+		if got := substringDepth(test.word, trie); got != test.expected {
+			t.Errorf("substringDepth(%q) = %v; want %v", test.word, got, test.expected)
+		}
+	}
+}
+
+func TestPrefixDepth(t *testing.T) {
+	trie := NewTrie(map[string]int64{"street": 10, "strong": 4})
+
+	tests := []struct {
+		word     string
+		expected float64
+	}{
+		{"strx", 0.75},
+		{"stre", 1},
+		{"xtre", 0},
+		{"", 0},
+	}
+
+	for _, test := range tests {
+		if got := prefixDepth(test.word, trie); got != test.expected {
+			t.Errorf("prefixDepth(%q) = %v; want %v", test.word, got, test.expected)
+		}
+	}
+}
+
+func TestScoreWordTrie(t *testing.T) {
+	words := map[string]int64{"street": 1000000, "tops": 1000000}
+	dict := NewDictionary(words, English)
+	cipher := completeCipher(t, Cipher{"t": "s", "o": "t", "p": "r", "s": "a"}) // "tops" -> "stra", 3 of 4 letters start "street"
+
+	without := Score(dict, cipher, false)
+	dict.WordTrie = NewTrie(words)
+	with := Score(dict, cipher, false)
+
+	want := prefixWeight * 0.75 * occurrenceScore(1000000)
+	if diff := with - without - want; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Score() with WordTrie added %.4f; want %.4f", with-without, want)
+	}
+}
+
+func TestScorePartialWordTrie(t *testing.T) {
+	words := map[string]int64{"street": 1000000, "tops": 1000000}
+	dict := NewDictionary(words, English)
+	cipher := completeCipher(t, Cipher{"t": "s", "o": "t", "p": "r", "s": "a"})
+
+	without := Score(dict, cipher, false)
+	dict.PartialWordTrie = NewTrie(CreatePartialWordDictionary(words))
+	with := Score(dict, cipher, false)
+
+	want := 0.0
+	for word, count := range words {
+		if encoded := encodeWord(word, cipher); words[encoded] == 0 {
+			want += substringWeight * substringDepth(encoded, dict.PartialWordTrie) * occurrenceScore(count)
+		}
+	}
+	if want == 0 {
+		t.Fatalf("no encoding occurs inside a word")
+	}
+	if diff := with - without - want; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Score() with PartialWordTrie added %.4f; want %.4f", with-without, want)
+	}
+}
@@ -0,0 +1,120 @@
+package sifo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ValidationError lists everything wrong with a cipher.
+type ValidationError struct {
+	Problems []error
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problems[i] = problem.Error()
+	}
+	return "invalid cipher: " + strings.Join(problems, "; ")
+}
+
+// Unwrap returns the problems, so that errors.Is finds ErrAmbiguousCipher among them.
+func (e *ValidationError) Unwrap() []error {
+	return e.Problems
+}
+
+// Validate checks a cipher over the alphabet and returns a *ValidationError listing all of its problems, or nil if it
+// has none. A cipher must have a lower case key for every letter of the alphabet, with keys and values made of
+// letters of the alphabet, and no key may encode to itself. A cipher of single letters must encode the keys to a
+// permutation of themselves. A cipher with multi-character keys or values, such as a digraph cipher, must instead be
+// decodable (see CheckDecodable).
+//
+// Ciphers are validated where they come into a search, by Score and by FindBestCipher for the giants and random
+// ciphers it starts from, and before encoding by EncodeChecked, EncodeTextChecked, NewEncoder and Protection. Encode
+// and EncodeText take any cipher and pass through letters it has no key for, so use their checked forms for a cipher
+// parsed or edited by hand.
+func Validate(cipher Cipher, alphabet []rune) error {
+	var problems []error
+	report := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	inAlphabet := make(map[rune]bool, len(alphabet))
+	for _, r := range alphabet {
+		inAlphabet[r] = true
+	}
+
+	keys := make([]string, 0, len(cipher))
+	for key := range cipher {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	singleLetters := true
+	for _, key := range keys {
+		value := cipher[key]
+		if key == "" {
+			report("empty key")
+		}
+		if value == "" {
+			report("key %q has an empty value", key)
+		}
+		for _, s := range []struct{ what, text string }{{"key", key}, {"value", value}} {
+			switch {
+			case strings.IndexFunc(s.text, unicode.IsUpper) >= 0:
+				report("%s %q is not lower case", s.what, s.text)
+			case strings.IndexFunc(s.text, func(r rune) bool { return !inAlphabet[r] }) >= 0:
+				report("%s %q has letters that are not in the alphabet", s.what, s.text)
+			}
+			if utf8.RuneCountInString(s.text) > 1 {
+				singleLetters = false
+			}
+		}
+		if key == value {
+			report("%q encodes to itself", key)
+		}
+	}
+
+	var missing []string
+	for _, r := range alphabet {
+		if _, ok := cipher[string(r)]; !ok {
+			missing = append(missing, string(r))
+		}
+	}
+	if len(missing) > 0 {
+		report("no key for %s", strings.Join(missing, ", "))
+	}
+
+	if singleLetters {
+		owners := make(map[string]string, len(cipher))
+		for _, key := range keys {
+			value := cipher[key]
+			if other, ok := owners[value]; ok {
+				problems = append(problems, fmt.Errorf("%w: %q and %q both encode to %q", ErrAmbiguousCipher, other, key, value))
+			}
+			owners[value] = key
+			if _, ok := cipher[value]; !ok && value != "" {
+				report("value %q is not a key", value)
+			}
+		}
+		var unused []string
+		for _, key := range keys {
+			if _, ok := owners[key]; !ok {
+				unused = append(unused, key)
+			}
+		}
+		if len(unused) > 0 {
+			report("nothing encodes to %s", strings.Join(unused, ", "))
+		}
+	} else if err := CheckDecodable(cipher); err != nil {
+		problems = append(problems, err)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
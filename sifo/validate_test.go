@@ -0,0 +1,178 @@
+package sifo
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// completeCipher returns the partial cipher with the letters it leaves out mapped among themselves, none to itself,
+// so that a test can give only the letters that matter and still pass Validate.
+func completeCipher(t *testing.T, partial Cipher) Cipher {
+	t.Helper()
+	cipher := make(Cipher, len(English.Alphabet))
+	used := make(map[string]bool)
+	for key, value := range partial {
+		cipher[key] = value
+		used[value] = true
+	}
+
+	var keys, values []string
+	for _, letter := range English.Letters() {
+		if _, ok := partial[letter]; !ok {
+			keys = append(keys, letter)
+		}
+		if !used[letter] {
+			values = append(values, letter)
+		}
+	}
+
+shifts:
+	for shift := range values {
+		for i, key := range keys {
+			if values[(i+shift)%len(values)] == key {
+				continue shifts
+			}
+		}
+		for i, key := range keys {
+			cipher[key] = values[(i+shift)%len(values)]
+		}
+		if err := Validate(cipher, English.Alphabet); err != nil {
+			t.Fatalf("completeCipher(%v) = %v", partial, err)
+		}
+		return cipher
+	}
+	if len(keys) > 0 {
+		t.Fatalf("completeCipher(%v) cannot map %v to %v without a fixed point", partial, keys, values)
+	}
+	return cipher
+}
+
+func TestValidate(t *testing.T) {
+	for _, cipher := range []Cipher{LonelyRemarkCipher(), WarmHoldCipher(), WormHelpCipher(), MoonPeerCipher(), WormHeldCipher()} {
+		if err := Validate(cipher, English.Alphabet); err != nil {
+			t.Errorf("Validate() of a giant error = %v", err)
+		}
+	}
+
+	valid := WarmHoldCipher()
+	with := func(changes Cipher, deletes ...string) Cipher {
+		cipher := make(Cipher)
+		for k, v := range valid {
+			cipher[k] = v
+		}
+		for k, v := range changes {
+			cipher[k] = v
+		}
+		for _, k := range deletes {
+			delete(cipher, k)
+		}
+		return cipher
+	}
+
+	tests := []struct {
+		name   string
+		cipher Cipher
+		want   []string
+	}{
+		{"missing letters", with(nil, "j", "q"), []string{"no key for j, q", `value "q" is not a key`, "nothing encodes to x"}},
+		{"fixed point", with(Cipher{"a": "a"}), []string{`"a" encodes to itself`, `"a" and "e" both encode to "a"`, "nothing encodes to o"}},
+		{"duplicate value", with(Cipher{"b": "o"}), []string{`both encode to "o"`, "nothing encodes to"}},
+		{"upper case key", with(Cipher{"A": "o"}, "a"), []string{`key "A" is not lower case`, "no key for a"}},
+		{"upper case value", with(Cipher{"a": "O"}), []string{`value "O" is not lower case`}},
+		{"not in the alphabet", with(Cipher{"ñ": "x"}), []string{`key "ñ" has letters that are not in the alphabet`}},
+		{"empty value", with(Cipher{"a": ""}), []string{`key "a" has an empty value`}},
+		{"ambiguous digraphs", with(Cipher{"th": "ow"}), []string{"not uniquely decodable"}},
+	}
+
+	for _, test := range tests {
+		err := Validate(test.cipher, English.Alphabet)
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) {
+			t.Errorf("Validate() with %s error = %v; want a *ValidationError", test.name, err)
+			continue
+		}
+		for _, want := range test.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Validate() with %s error = %v; want it to contain %q", test.name, err, want)
+			}
+		}
+	}
+
+	if err := Validate(with(Cipher{"th": "ow"}), English.Alphabet); !errors.Is(err, ErrAmbiguousCipher) {
+		t.Errorf("Validate() of an ambiguous digraph cipher error = %v; want it to wrap ErrAmbiguousCipher", err)
+	}
+}
+
+func TestValidateDigraphCipher(t *testing.T) {
+	cipher := generateRandomDigraphCipher(rand.New(rand.NewSource(1)), English, CommonDigraphs)
+	if err := Validate(cipher, English.Alphabet); err != nil {
+		t.Errorf("Validate() of a digraph cipher error = %v", err)
+	}
+}
+
+func TestEntryPointsValidate(t *testing.T) {
+	bad := WarmHoldCipher()
+	bad["a"] = "a"
+
+	dict := NewDictionary(map[string]int64{"warm": 1}, English)
+	if score := Score(dict, bad, false); !math.IsInf(score, -1) {
+		t.Errorf("Score() of an invalid cipher = %v; want -Inf", score)
+	}
+
+	// Encode and EncodeText take any cipher, including one over another alphabet.
+	if got, want := Encode("warm", bad), "hald "; got != want {
+		t.Errorf("Encode() with an invalid cipher = %q; want %q", got, want)
+	}
+	if got, want := Spanish.Encode("año", WarmHoldCipher()), "oñe "; got != want {
+		t.Errorf("Spanish.Encode() with an English cipher = %q; want %q", got, want)
+	}
+
+	// Their checked forms validate the cipher first.
+	var validation *ValidationError
+	if _, err := EncodeChecked("warm", bad); !errors.As(err, &validation) {
+		t.Errorf("EncodeChecked() with an invalid cipher error = %v; want a *ValidationError", err)
+	}
+	if _, err := EncodeTextChecked("warm", bad); !errors.As(err, &validation) {
+		t.Errorf("EncodeTextChecked() with an invalid cipher error = %v; want a *ValidationError", err)
+	}
+	if _, err := Spanish.EncodeTextChecked("año", WarmHoldCipher()); !errors.As(err, &validation) {
+		t.Errorf("Spanish.EncodeTextChecked() with an English cipher error = %v; want a *ValidationError", err)
+	}
+	if got, err := EncodeChecked("warm", WarmHoldCipher()); err != nil || got != "hold " {
+		t.Errorf("EncodeChecked() = %q, %v; want %q", got, err, "hold ")
+	}
+	if got, err := EncodeTextChecked("Warm, hold.", WarmHoldCipher()); err != nil || got != "Hold, yern." {
+		t.Errorf("EncodeTextChecked() = %q, %v; want %q", got, err, "Hold, yern.")
+	}
+
+	dict.Digraphs = []string{"TH"}
+	if _, err := FindBestCipher(dict, 10); err == nil || !strings.Contains(err.Error(), "not lower case") {
+		t.Errorf("FindBestCipher() with upper case digraphs error = %v; want a validation error", err)
+	}
+
+	// With no giants that fit, the random ciphers seeded in their place are checked too.
+	spanish := NewDictionary(map[string]int64{"año": 1}, Spanish)
+	spanish.Digraphs = []string{"TH"}
+	if _, err := FindBestCipher(spanish, 10); err == nil || !strings.Contains(err.Error(), "not lower case") {
+		t.Errorf("FindBestCipher() of a Spanish dictionary with upper case digraphs error = %v; want a validation error", err)
+	}
+}
+
+func TestFindBestCipherSpanish(t *testing.T) {
+	words := map[string]int64{"año": 1000000, "niño": 800000, "mañana": 500000, "sí": 400000, "más": 300000}
+	dict := NewDictionary(words, Spanish)
+	if gts := giants(dict); len(gts) != 0 {
+		t.Fatalf("giants() of a Spanish dictionary = %d giants; want none, as they have no key for ñ", len(gts))
+	}
+
+	cipher, err := FindBestCipher(dict, 100)
+	if err != nil {
+		t.Fatalf("FindBestCipher() error = %v", err)
+	}
+	if err := Validate(cipher, Spanish.Alphabet); err != nil {
+		t.Errorf("FindBestCipher() of a Spanish dictionary = %v; %v", cipher, err)
+	}
+}